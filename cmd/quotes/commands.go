@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	serviceAPI "github.com/Grino777/quotes/internal/services/api"
+	"github.com/Grino777/quotes/internal/services/audit"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// runCommand выполняет служебную команду вместо запуска сервера.
+func runCommand(log *slog.Logger, args []string) error {
+	switch args[0] {
+	case "purge":
+		return runPurge(log, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, available: purge", args[0])
+	}
+}
+
+// runPurge окончательно удаляет цитаты, пролежавшие в корзине дольше
+// срока хранения (trash.retention или флаг -older-than).
+func runPurge(log *slog.Logger, args []string) error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	flags := flag.NewFlagSet("purge", flag.ContinueOnError)
+	retention := flags.Duration("older-than", cfg.Trash.Retention,
+		"удалить цитаты, пролежавшие в корзине дольше этого срока")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *retention < 0 {
+		return errors.New("-older-than must not be negative")
+	}
+
+	storage := sqlite.NewStorage(log, &cfg.SQLite)
+	if err := storage.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer storage.Close()
+
+	auditLog, err := audit.NewLog(log, cfg.Audit.File)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+	storage.OnAudit(auditLog.Mirror)
+
+	ctx := actor.WithActor(context.Background(), actor.CLI)
+	if _, err := serviceAPI.NewService(log, storage, nil).PurgeTrash(ctx, *retention); err != nil {
+		return fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	return nil
+}
@@ -11,25 +11,39 @@ import (
 )
 
 func main() {
-	stop := make(chan os.Signal, 1)
 	log := logger.NewLogger(os.Stdout, slog.LevelDebug)
 
+	if len(os.Args) > 1 {
+		if err := runCommand(log, os.Args[1:]); err != nil {
+			log.Error("command failed", logger.Error(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+
 	app, err := app.NewApp(log)
 	if err != nil {
 		log.Error("failed to create app obj", logger.Error(err))
 		return
 	}
 
-	if err := app.Run(); err != nil {
-		if err := app.Stop(); err != nil {
-			log.Error("failed to stop app", logger.Error(err))
+	// Run блокируется до остановки, поэтому сигналы ждём параллельно
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- app.Run()
+	}()
+
+	select {
+	case <-stop:
+	case err := <-errChan:
+		if err != nil {
+			log.Error("app stopped with error", logger.Error(err))
 		}
-		return
 	}
 
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
-	<-stop
-
 	if err := app.Stop(); err != nil {
 		log.Error("failed to stop app", logger.Error(err))
 	}
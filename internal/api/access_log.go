@@ -0,0 +1,151 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/lib/clientip"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+)
+
+const (
+	AccessLogJSON     = "json"
+	AccessLogCombined = "combined"
+	AccessLogOff      = "off"
+)
+
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogger пишет по строке на каждый запрос в выбранном формате.
+type AccessLogger struct {
+	format     string
+	out        io.Writer
+	closer     io.Closer
+	json       *slog.Logger
+	sampling   map[string]float64
+	trustProxy bool
+	mu         sync.Mutex
+}
+
+func NewAccessLogger(cfg *config.AccessLogConfig, trustProxy bool) (*AccessLogger, error) {
+	al := &AccessLogger{
+		format:     cfg.Format,
+		sampling:   cfg.Sampling,
+		trustProxy: trustProxy,
+	}
+
+	switch cfg.Format {
+	case AccessLogOff:
+		return al, nil
+	case AccessLogJSON, AccessLogCombined:
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", cfg.Format)
+	}
+
+	al.out = os.Stdout
+	if cfg.Output != "" {
+		rf, err := logger.NewRotatingFile(cfg.Output, cfg.MaxSizeMB, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		al.out = rf
+		al.closer = rf
+	}
+
+	if cfg.Format == AccessLogJSON {
+		al.json = slog.New(slog.NewJSONHandler(al.out, nil))
+	}
+
+	return al, nil
+}
+
+func (al *AccessLogger) Close() error {
+	if al.closer != nil {
+		return al.closer.Close()
+	}
+	return nil
+}
+
+func AccessLogMiddleware(al *AccessLogger, next http.Handler) http.Handler {
+	if al.format == AccessLogOff {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := wrapRecorder(w)
+
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		if al.sampled(r.URL.Path, rec.Status()) {
+			al.log(r, rec, start, time.Since(start))
+		}
+	})
+}
+
+// sampled решает, попадёт ли запрос в лог. Ошибки логируются всегда.
+func (al *AccessLogger) sampled(path string, status int) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	rate, ok := al.sampling[path]
+	if !ok || rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+func (al *AccessLogger) log(r *http.Request, rec *responseRecorder, start time.Time, duration time.Duration) {
+	ip := clientip.FromRequest(r, al.trustProxy)
+
+	if al.format == AccessLogJSON {
+		al.json.Info("access",
+			slog.String("request_id", requestid.FromContext(r.Context())),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.RequestURI()),
+			slog.String("proto", r.Proto),
+			slog.Int("status", rec.Status()),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("duration_sec", duration.Seconds()),
+			slog.String("client_ip", ip),
+			slog.String("user_agent", r.UserAgent()),
+			slog.String("referer", r.Referer()),
+		)
+		return
+	}
+
+	size := "-"
+	if rec.bytes > 0 {
+		size = strconv.FormatInt(rec.bytes, 10)
+	}
+
+	line := fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %s %q %q\n",
+		ip,
+		start.Format(combinedTimeFormat),
+		r.Method, r.URL.RequestURI(), r.Proto,
+		rec.Status(),
+		size,
+		orDash(r.Referer()),
+		orDash(r.UserAgent()),
+	)
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	_, _ = io.WriteString(al.out, line)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -2,13 +2,19 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
-	"strconv"
-	"strings"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Grino777/quotes/internal/api/graphqlapi"
+	"github.com/Grino777/quotes/internal/config"
 	"github.com/Grino777/quotes/internal/domain/models"
 	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/logger"
 )
 
 const (
@@ -17,126 +23,107 @@ const (
 )
 
 type API struct {
-	logger  *slog.Logger
-	service interfaces.Service
+	logger       *slog.Logger
+	service      interfaces.Service
+	events       interfaces.EventSubscriber
+	maxBodyBytes int64
+	// Интервал комментариев-пульсов в потоке событий
+	heartbeat    time.Duration
+	shuttingDown atomic.Bool
+	// Ротация цитат по WebSocket: ограничения, разрешённые источники и
+	// учёт открытых соединений для остановки сервера
+	ws          config.WebSocketConfig
+	originHosts []string
+	sockets     sync.WaitGroup
+	closing     chan struct{}
+	closeOnce   sync.Once
+	graphql     *graphqlapi.Executor
+	// Сколько хранятся ответы на запросы с Idempotency-Key
+	idempotencyTTL time.Duration
+	// Возраст, в котором вес отметки в рейтинге популярности падает вдвое
+	popularHalfLife time.Duration
+	// Внутренние сети, куда разрешено слать webhooks
+	webhookNetworks []netip.Prefix
 }
 
 func NewApi(
 	log *slog.Logger,
 	service interfaces.Service,
-) *API {
-	return &API{logger: log, service: service}
-}
-
-func (a *API) HomeRoute(w http.ResponseWriter, r *http.Request) {
-	if err := json.NewEncoder(w).Encode(map[string]string{"result": "Quotes API"}); err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-		return
-	}
-}
-
-func (a *API) NotFound(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "not found", http.StatusNotFound)
-}
-
-func (a *API) NotFoundFallback(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		a.NotFound(w, r)
-	} else {
-		a.HomeRoute(w, r)
+	events interfaces.EventSubscriber,
+	cfg *config.APIConfig,
+	webhookNetworks []netip.Prefix,
+) (*API, error) {
+	executor, err := graphqlapi.NewExecutor(service, &cfg.GraphQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graphql schema: %w", err)
 	}
 
+	return &API{
+		logger:       log,
+		service:      service,
+		events:       events,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		heartbeat:    cfg.Stream.Heartbeat,
+		ws:           cfg.WebSocket,
+		originHosts:  originHosts(cfg.CORS.AllowedOrigins),
+		closing:      make(chan struct{}),
+		graphql:      executor,
+
+		idempotencyTTL:  cfg.Idempotency.TTL,
+		popularHalfLife: cfg.Popular.HalfLife,
+		webhookNetworks: webhookNetworks,
+	}, nil
 }
 
-func (a *API) AllQuotes(w http.ResponseWriter, r *http.Request) {
-	author := r.URL.Query().Get("author")
-	if author != "" {
-		data, err := a.service.FilterQuotes(r.Context(), author)
-		if err != nil {
-			http.Error(w, InternalError, http.StatusInternalServerError)
-		}
-		_, err = w.Write(data)
-		if err != nil {
-			http.Error(w, InternalError, http.StatusInternalServerError)
-			return
-		}
-	} else {
-		data, err := a.service.GetQuotes(r.Context())
-		if err != nil {
-			http.Error(w, InternalError, http.StatusInternalServerError)
-		}
-		_, err = w.Write(data)
-		if err != nil {
-			http.Error(w, InternalError, http.StatusInternalServerError)
-			return
-		}
-	}
+// quoteInput — тело запроса на создание цитаты.
+type quoteInput struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
 }
 
-func (a *API) CreateQuote(w http.ResponseWriter, r *http.Request) {
-	var q models.Quote
+// decodeQuote читает тело запроса на создание цитаты функцией decode и
+// валидирует его. Если возвращено false, ответ с ошибкой уже отправлен.
+func (a *API) decodeQuote(w http.ResponseWriter, r *http.Request, decode decodeFunc) (models.Quote, bool) {
+	var in quoteInput
 
-	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-		return
+	verrs, err := decode(w, r, a.maxBodyBytes, &in)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return models.Quote{}, false
 	}
 
-	if err := q.Validate(); err != nil {
-		http.Error(w, "fields author and quote is required", http.StatusBadRequest)
-		return
-	}
+	q := models.Quote{Author: in.Author, Quote: in.Quote}
+	q.Normalize()
 
-	res, err := a.service.CreateQuote(r.Context(), q)
-	if err != nil {
-		if err := json.NewEncoder(w).Encode(
-			map[string][]byte{"result": res}); err != nil {
-			http.Error(w, InternalError, http.StatusInternalServerError)
-		}
+	if verrs = verrs.Merge(q.Validate()); len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return models.Quote{}, false
 	}
 
-	_, err = w.Write(res)
-	if err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-		return
-	}
+	return q, true
 }
 
-func (a *API) RandomQuote(w http.ResponseWriter, r *http.Request) {
-	data, err := a.service.GetRandomQuote(r.Context())
-	if err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
+func (a *API) HomeRoute(w http.ResponseWriter, r *http.Request) {
+	if err := json.NewEncoder(w).Encode(map[string]string{"result": "Quotes API"}); err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
 		return
 	}
 }
 
-func (a *API) DeleteQuote(w http.ResponseWriter, r *http.Request) {
-	pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(pathParts) < 2 || pathParts[0] != "quotes" {
-		http.Error(w, "invalid quote ID", http.StatusBadRequest)
-		return
-	}
-	idStr := pathParts[1]
+func (a *API) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, "not found")
+}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "invalid quote ID", http.StatusBadRequest)
-		return
+func (a *API) NotFoundFallback(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		a.NotFound(w, r)
+	} else {
+		a.HomeRoute(w, r)
 	}
 
-	res, err := a.service.DeleteQuote(r.Context(), id)
-	if err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-		return
-	}
+}
 
-	_, err = w.Write(res)
-	if err != nil {
-		http.Error(w, InternalError, http.StatusInternalServerError)
-		return
-	}
+// Заголовки уже отправлены, поэтому ошибку записи можно только залогировать
+func (a *API) logWriteError(r *http.Request, err error) {
+	logger.FromContext(r.Context(), a.logger).Warn("failed to write response", logger.Error(err))
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// ListAudit возвращает журнал аудита с фильтрами actor, action, entity_id
+// и интервалом from/to (RFC 3339, to не включается).
+func (a *API) ListAudit(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	entries, total, err := a.service.ListAudit(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, entries, filter.Limit, filter.Offset, total))
+}
+
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (models.AuditFilter, bool) {
+	var verrs models.ValidationErrors
+	query := r.URL.Query()
+
+	filter := models.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+	}
+
+	var ferr *models.FieldError
+	if filter.Limit, ferr = queryInt(r, "limit", models.DefaultPageLimit); ferr != nil {
+		verrs = append(verrs, *ferr)
+	}
+	if filter.Offset, ferr = queryInt(r, "offset", 0); ferr != nil {
+		verrs = append(verrs, *ferr)
+	}
+
+	if raw := query.Get("entity_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			verrs = append(verrs, models.FieldError{Field: "entity_id", Message: "must be a positive integer"})
+		}
+		filter.EntityID = id
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := query.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			verrs = append(verrs, models.FieldError{Field: p.name, Message: "must be an RFC 3339 timestamp"})
+			continue
+		}
+		*p.dst = t
+	}
+
+	if verrs = verrs.Merge(filter.Validate()); len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return models.AuditFilter{}, false
+	}
+
+	return filter, true
+}
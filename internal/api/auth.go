@@ -0,0 +1,69 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/lib/auth"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+const bearerChallenge = `Bearer realm="quotes"`
+
+// AuthMiddleware проверяет ключ из заголовка Authorization: Bearer. Запрос
+// без заголовка проходит анонимно, неверный ключ получает 401.
+func AuthMiddleware(log *slog.Logger, keys *auth.Keys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(auth.Header)
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, ok := auth.Principal{}, false
+			if token, valid := auth.BearerToken(header); valid {
+				principal, ok = keys.Lookup(token)
+			}
+			if !ok {
+				logger.FromContext(r.Context(), log).Warn("invalid api key")
+				w.Header().Set("WWW-Authenticate", bearerChallenge+`, error="invalid_token"`)
+				writeError(w, r, http.StatusUnauthorized, "invalid api key")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireKey пропускает только клиентов с действительным ключом API.
+func RequireKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.FromContext(r.Context()); !ok {
+			writeKeyRequired(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin пропускает только клиентов с административным ключом.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.FromContext(r.Context())
+		switch {
+		case !ok:
+			writeKeyRequired(w, r)
+		case !principal.Admin:
+			writeError(w, r, http.StatusForbidden, "admin api key is required")
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func writeKeyRequired(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", bearerChallenge)
+	writeError(w, r, http.StatusUnauthorized, "api key is required")
+}
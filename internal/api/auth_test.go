@@ -0,0 +1,118 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/auth"
+)
+
+func keySum(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	keys, err := auth.NewKeys([]config.APIKeyConfig{
+		{Subject: "ops", SHA256: keySum("admin-key"), Admin: true},
+		{Subject: "reader", SHA256: keySum("reader-key")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	public := AuthMiddleware(log, keys)(ok)
+	keyed := AuthMiddleware(log, keys)(RequireKey(ok))
+	admin := AuthMiddleware(log, keys)(RequireAdmin(ok))
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		header  string
+		want    int
+	}{
+		{"public anonymous", public, "", http.StatusNoContent},
+		{"public valid key", public, "Bearer reader-key", http.StatusNoContent},
+		{"public invalid key", public, "Bearer wrong", http.StatusUnauthorized},
+		{"public other scheme", public, "Basic cmVhZGVyLWtleQ==", http.StatusUnauthorized},
+		{"keyed anonymous", keyed, "", http.StatusUnauthorized},
+		{"keyed valid key", keyed, "Bearer reader-key", http.StatusNoContent},
+		{"admin anonymous", admin, "", http.StatusUnauthorized},
+		{"admin non-admin key", admin, "Bearer reader-key", http.StatusForbidden},
+		{"admin admin key", admin, "bearer admin-key", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestNewKeysRejectsMalformedHash(t *testing.T) {
+	if _, err := auth.NewKeys([]config.APIKeyConfig{{Subject: "ops", SHA256: "abc"}}); err == nil {
+		t.Fatal("expected error for short hash")
+	}
+}
+
+func TestActorBoundToKey(t *testing.T) {
+	keys, err := auth.NewKeys([]config.APIKeyConfig{{Subject: "reader", SHA256: keySum("reader-key")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := AuthMiddleware(log, keys)(ActorMiddleware(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = actor.FromContext(r.Context())
+	})))
+
+	tests := []struct {
+		name   string
+		key    string
+		header string
+		want   string
+	}{
+		{"key overrides header", "Bearer reader-key", "mallory", "reader"},
+		{"anonymous header", "", "alice", "alice"},
+		{"anonymous without header", "", "", actor.Anonymous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodDelete, "/quotes/1", nil)
+			if tt.key != "" {
+				r.Header.Set(auth.Header, tt.key)
+			}
+			if tt.header != "" {
+				r.Header.Set(actor.Header, tt.header)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Fatalf("actor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
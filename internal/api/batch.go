@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// batchInput — тело запроса пакетных операций.
+type batchInput struct {
+	Mode       models.BatchMode `json:"mode"`
+	Operations []batchOpInput   `json:"operations"`
+}
+
+type batchOpInput struct {
+	Op      models.BatchOp `json:"op"`
+	ID      int            `json:"id"`
+	Version int64          `json:"version"`
+	Author  string         `json:"author"`
+	Quote   string         `json:"quote"`
+}
+
+// batchResult — ответ на пакет. Status у операции — HTTP-статус, которым
+// ответил бы соответствующий одиночный запрос.
+type batchResult struct {
+	Mode      models.BatchMode `json:"mode"`
+	Committed bool             `json:"committed"`
+	Results   []batchOpResult  `json:"results"`
+}
+
+type batchOpResult struct {
+	Index  int                     `json:"index"`
+	Op     models.BatchOp          `json:"op"`
+	Status int                     `json:"status"`
+	ID     int                     `json:"id,omitempty"`
+	Quote  *models.Quote           `json:"quote,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+	Errors models.ValidationErrors `json:"errors,omitempty"`
+}
+
+var batchStatuses = map[models.BatchOp]int{
+	models.BatchCreate: http.StatusCreated,
+	models.BatchUpdate: http.StatusOK,
+	models.BatchDelete: http.StatusNoContent,
+}
+
+// BatchOps выполняет создание, изменение и удаление цитат в одной
+// транзакции. Ошибки отдельных операций возвращаются в results, а не
+// статусом ответа.
+func (a *API) BatchOps(w http.ResponseWriter, r *http.Request) {
+	var in batchInput
+
+	verrs, err := decodeJSON(w, r, a.maxBodyBytes, &in)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	if in.Mode == "" {
+		in.Mode = models.BatchAtomic
+	}
+	if in.Mode != models.BatchAtomic && in.Mode != models.BatchBestEffort {
+		verrs = append(verrs, models.FieldError{Field: "mode", Message: "must be one of atomic, best_effort"})
+	}
+	if len(in.Operations) == 0 || len(in.Operations) > models.MaxBatchOperations {
+		verrs = append(verrs, models.FieldError{
+			Field:   "operations",
+			Message: fmt.Sprintf("must contain from 1 to %d operations", models.MaxBatchOperations),
+		})
+	}
+	if len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return
+	}
+
+	ops := make([]models.BatchOperation, len(in.Operations))
+	for i, o := range in.Operations {
+		ops[i] = models.BatchOperation{
+			Op:      o.Op,
+			ID:      o.ID,
+			Version: o.Version,
+			Quote:   models.Quote{Author: o.Author, Quote: o.Quote},
+		}
+		ops[i].Quote.Normalize()
+	}
+
+	result, err := a.service.ApplyBatch(r.Context(), in.Mode, ops)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	out := batchResult{
+		Mode:      result.Mode,
+		Committed: result.Committed,
+		Results:   make([]batchOpResult, len(result.Results)),
+	}
+	for i, res := range result.Results {
+		item := batchOpResult{Index: res.Index, Op: res.Op, ID: res.ID, Quote: res.Quote}
+
+		var fieldErrs models.ValidationErrors
+		switch {
+		case res.Err == nil:
+			item.Status = batchStatuses[res.Op]
+		case errors.As(res.Err, &fieldErrs):
+			item.Status = http.StatusUnprocessableEntity
+			item.Error = "operation validation failed"
+			item.Errors = fieldErrs
+		default:
+			item.Status, item.Error = serviceErrorStatus(res.Err)
+		}
+		out.Results[i] = item
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newItem(r, out))
+}
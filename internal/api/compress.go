@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+)
+
+// encoder — потоковый компрессор, который можно переиспользовать через Reset.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+type zstdEncoder struct {
+	*zstd.Encoder
+}
+
+func (ze zstdEncoder) Reset(w io.Writer) {
+	ze.Encoder.Reset(w)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	EncodingGzip: {New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+	EncodingBrotli: {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}},
+	EncodingZstd: {New: func() any {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return zstdEncoder{w}
+	}},
+}
+
+// compressibleTypes — типы содержимого, которые имеет смысл сжимать.
+var compressibleTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"application/x-ndjson",
+	"text/",
+}
+
+// CompressionMiddleware сжимает ответ алгоритмом из Accept-Encoding клиента.
+// Ответы меньше minSize отдаются как есть. Flush обработчика сразу включает
+// сжатие и сбрасывает кодировщик, поэтому потоковые ответы доходят до
+// клиента без задержки.
+func CompressionMiddleware(cfg *config.CompressionConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), cfg.Encodings)
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{w: w, encoding: encoding, minSize: cfg.MinSize}
+			next.ServeHTTP(cw, r)
+			// Не через defer: при панике частичный ответ не должен уйти клиенту,
+			// RecoveryMiddleware ответит 500 сам
+			cw.finish()
+		})
+	}
+}
+
+// negotiateEncoding выбирает кодировку с наибольшим q из поддерживаемых,
+// при равных q — в порядке supported.
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		weights[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range supported {
+		q, ok := weights[enc]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressWriter копит начало ответа, пока не станет ясно, стоит ли его
+// сжимать: размер достиг порога, обработчик вызвал Flush или закончил работу.
+type compressWriter struct {
+	w        http.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf     bytes.Buffer
+	code    int
+	decided bool
+	enc     encoder
+}
+
+func (cw *compressWriter) Header() http.Header {
+	return cw.w.Header()
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.code != 0 {
+		return
+	}
+	cw.code = code
+
+	// Ответы без тела отдаём сразу
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.w.Write(p)
+	}
+
+	cw.buf.Write(p)
+	if cw.buf.Len() >= cw.minSize {
+		if err := cw.start(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.code == 0 {
+			cw.code = http.StatusOK
+		}
+		if err := cw.start(); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	if f, ok := cw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.w
+}
+
+// start принимает решение о сжатии и отправляет накопленный буфер.
+func (cw *compressWriter) start() error {
+	cw.decide(cw.compressible())
+
+	data := cw.buf.Bytes()
+	cw.buf = bytes.Buffer{}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(data)
+	} else {
+		_, err = cw.w.Write(data)
+	}
+	return err
+}
+
+func (cw *compressWriter) decide(compress bool) {
+	cw.decided = true
+
+	if compress {
+		h := cw.w.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		// Сжатое представление отличается от исходного, поэтому у него свой ETag
+		if etag := h.Get("ETag"); strings.HasSuffix(etag, `"`) {
+			h.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+cw.encoding+`"`)
+		}
+
+		cw.enc = encoderPools[cw.encoding].Get().(encoder)
+		cw.enc.Reset(cw.w)
+	}
+
+	if cw.code == 0 {
+		cw.code = http.StatusOK
+	}
+	cw.w.WriteHeader(cw.code)
+}
+
+func (cw *compressWriter) compressible() bool {
+	h := cw.w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	ct := h.Get("Content-Type")
+	for _, t := range compressibleTypes {
+		if strings.HasPrefix(ct, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish отправляет остаток ответа: маленький ответ — без сжатия,
+// сжатый — закрывая кодировщик.
+func (cw *compressWriter) finish() {
+	if !cw.decided {
+		if cw.code == 0 && cw.buf.Len() == 0 {
+			// Обработчик ничего не записал, net/http ответит 200 сам
+			return
+		}
+		cw.decide(false)
+		_, _ = cw.w.Write(cw.buf.Bytes())
+		return
+	}
+
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		encoderPools[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
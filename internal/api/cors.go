@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/config"
+)
+
+// CORS добавляет заголовки Cross-Origin Resource Sharing для разрешённых
+// источников и отвечает на preflight-запросы.
+type CORS struct {
+	cfg *config.CORSConfig
+}
+
+func NewCORS(cfg *config.CORSConfig) *CORS {
+	return &CORS{cfg: cfg}
+}
+
+// Middleware выставляет Access-Control-Allow-Origin и связанные заголовки
+// на ответы для разрешённых источников. Запросы без Origin не меняются.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin := r.Header.Get("Origin"); origin != "" && c.allowOrigin(w, origin) {
+			if !preflight && len(c.cfg.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(c.cfg.ExposedHeaders, ", "))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Preflight обрабатывает OPTIONS для маршрута, зарегистрированного с
+// методами methods. Запрос с недопустимым источником, методом или
+// заголовком получает ответ без CORS-заголовков, и браузер его отклонит.
+func (c *CORS) Preflight(methods []string) http.HandlerFunc {
+	allowed := make([]string, 0, len(methods))
+	for _, m := range methods {
+		if slices.Contains(c.cfg.AllowedMethods, m) {
+			allowed = append(allowed, m)
+		}
+	}
+	allow := strings.Join(append(slices.Clone(methods), http.MethodOptions), ", ")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Allow", allow)
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		requested := r.Header.Get("Access-Control-Request-Headers")
+		// Access-Control-Allow-Origin уже выставлен в Middleware, если источник разрешён
+		if h.Get("Access-Control-Allow-Origin") == "" ||
+			!slices.Contains(allowed, method) || !c.allowHeaders(requested) {
+			h.Del("Access-Control-Allow-Origin")
+			h.Del("Access-Control-Allow-Credentials")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		h.Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+		if requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		}
+		if c.cfg.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(c.cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// allowOrigin выставляет Access-Control-Allow-Origin, если источник
+// разрешён. С учётными данными источник возвращается явно, без "*".
+func (c *CORS) allowOrigin(w http.ResponseWriter, origin string) bool {
+	for _, pattern := range c.cfg.AllowedOrigins {
+		if !matchOrigin(pattern, origin) {
+			continue
+		}
+
+		h := w.Header()
+		if pattern == "*" && !c.cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.cfg.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		return true
+	}
+	return false
+}
+
+func (c *CORS) allowHeaders(requested string) bool {
+	if slices.Contains(c.cfg.AllowedHeaders, "*") {
+		return true
+	}
+
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.ContainsFunc(c.cfg.AllowedHeaders, func(h string) bool {
+			return strings.EqualFold(h, name)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOrigin сравнивает источник с шаблоном. Шаблон может содержать одну
+// звёздочку, например "https://*.example.com"; "*" разрешает любой источник.
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return strings.EqualFold(pattern, origin)
+	}
+
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// bodyError — тело запроса нельзя разобрать как JSON-объект.
+type bodyError struct {
+	status int
+	detail string
+}
+
+func (be *bodyError) Error() string {
+	return be.detail
+}
+
+// decodeFunc читает тело запроса в dst, см. decodeJSON.
+type decodeFunc func(w http.ResponseWriter, r *http.Request, maxBytes int64, dst any) (models.ValidationErrors, error)
+
+// decodeJSON читает тело не больше maxBytes и раскладывает JSON-объект по
+// полям структуры dst (по тегам json). Неизвестные и повторяющиеся поля и
+// значения неподходящего типа возвращаются списком, чтобы клиент увидел все
+// проблемы сразу. Ошибка возвращается, если тело вообще не удалось прочитать.
+func decodeJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, dst any) (models.ValidationErrors, error) {
+	return decodeBody(w, r, maxBytes, dst, false)
+}
+
+// decodeLegacyJSON работает как decodeJSON, но сравнивает имена полей без
+// учёта регистра, как encoding/json: клиенты v1 присылают и "Author".
+func decodeLegacyJSON(w http.ResponseWriter, r *http.Request, maxBytes int64, dst any) (models.ValidationErrors, error) {
+	return decodeBody(w, r, maxBytes, dst, true)
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, maxBytes int64, dst any, foldCase bool) (models.ValidationErrors, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, &bodyError{
+				status: http.StatusRequestEntityTooLarge,
+				detail: fmt.Sprintf("request body must not exceed %d bytes", maxBytes),
+			}
+		}
+		return nil, &bodyError{status: http.StatusBadRequest, detail: "failed to read request body"}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, &bodyError{status: http.StatusBadRequest, detail: "request body must not be empty"}
+	}
+
+	fields, err := readObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeFields(fields, dst, foldCase), nil
+}
+
+// jsonField — поле JSON-объекта в порядке появления в теле.
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// readObject разбирает JSON-объект по токенам: map молча оставил бы
+// последнее из повторяющихся полей.
+func readObject(data []byte) ([]jsonField, error) {
+	malformed := &bodyError{status: http.StatusBadRequest, detail: "request body contains malformed JSON"}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, malformed
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, &bodyError{status: http.StatusBadRequest, detail: "request body must be a JSON object"}
+	}
+
+	var fields []jsonField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, malformed
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, malformed
+		}
+		fields = append(fields, jsonField{key: tok.(string), value: value})
+	}
+
+	// Закрывающая скобка и ничего после неё
+	if _, err := dec.Token(); err != nil {
+		return nil, malformed
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, malformed
+	}
+
+	return fields, nil
+}
+
+// decodeFields раскладывает поля по структуре dst. Без foldCase имена
+// сравниваются с учётом регистра: "Author" или "AUTHOR" — неизвестные поля,
+// а не author. Повторное поле — ошибка, чтобы клиент и сервер не расходились
+// в том, какое из значений действует; с foldCase "author" и "Author" — тоже
+// повтор.
+func decodeFields(fields []jsonField, dst any, foldCase bool) models.ValidationErrors {
+	var errs models.ValidationErrors
+
+	name := func(key string) string { return key }
+	if foldCase {
+		name = strings.ToLower
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	index := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if tag := jsonName(t.Field(i)); tag != "" {
+			index[name(tag)] = i
+		}
+	}
+
+	seen := make(map[string]bool, len(fields))
+	var unknown, duplicate []string
+	for _, f := range fields {
+		key := name(f.key)
+		if seen[key] {
+			duplicate = append(duplicate, f.key)
+			continue
+		}
+		seen[key] = true
+
+		i, ok := index[key]
+		if !ok {
+			unknown = append(unknown, f.key)
+			continue
+		}
+		if err := json.Unmarshal(f.value, v.Field(i).Addr().Interface()); err != nil {
+			errs = append(errs, models.FieldError{Field: f.key, Message: typeMessage(err)})
+		}
+	}
+
+	sort.Strings(duplicate)
+	for _, key := range slices.Compact(duplicate) {
+		errs = append(errs, models.FieldError{Field: key, Message: "duplicate field"})
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		errs = append(errs, models.FieldError{Field: key, Message: "unknown field"})
+	}
+
+	return errs
+}
+
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return f.Name
+	}
+	return tag
+}
+
+func typeMessage(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return "must be a " + jsonTypeName(typeErr.Type)
+	}
+	return "invalid value"
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// writeDecodeError отвечает на ошибку чтения тела запроса.
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var be *bodyError
+	if errors.As(err, &be) {
+		writeError(w, r, be.status, be.detail)
+		return
+	}
+	writeError(w, r, http.StatusBadRequest, err.Error())
+}
+
+// writeValidationError отвечает 422 со списком проблем по полям.
+func writeValidationError(w http.ResponseWriter, r *http.Request, errs models.ValidationErrors) {
+	writeProblem(w, r, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: "request validation failed",
+		Errors: errs,
+	})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+type decodeTarget struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+}
+
+func TestDecodeJSONStrictFields(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []models.FieldError
+	}{
+		{"exact names", `{"author":"seneca","quote":"q"}`, nil},
+		{"other case is unknown", `{"AUTHOR":"seneca","quote":"q"}`, []models.FieldError{
+			{Field: "AUTHOR", Message: "unknown field"},
+		}},
+		{"case variant of a known field", `{"author":"seneca","Author":"nero","quote":"q"}`, []models.FieldError{
+			{Field: "Author", Message: "unknown field"},
+		}},
+		{"duplicate key", `{"author":"seneca","author":"nero","quote":"q"}`, []models.FieldError{
+			{Field: "author", Message: "duplicate field"},
+		}},
+		{"wrong type", `{"author":1,"quote":"q"}`, []models.FieldError{
+			{Field: "author", Message: "must be a string"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(tt.body))
+			var dst decodeTarget
+			errs, err := decodeJSON(httptest.NewRecorder(), r, 1<<10, &dst)
+			if err != nil {
+				t.Fatalf("unexpected body error: %v", err)
+			}
+			if !slices.Equal(errs, tt.want) {
+				t.Fatalf("errors = %v, want %v", errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONRejectsMalformedBody(t *testing.T) {
+	for _, body := range []string{`[]`, `null`, `{"author":`, `{"author":"a"} {}`, `{"author":"a"}x`} {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(body))
+		var dst decodeTarget
+		if _, err := decodeJSON(httptest.NewRecorder(), r, 1<<10, &dst); err == nil {
+			t.Errorf("body %q accepted", body)
+		}
+	}
+}
+
+func TestDecodeLegacyJSONFoldsCase(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []models.FieldError
+		dst  decodeTarget
+	}{
+		{"legacy names", `{"Author":"seneca","Quote":"q"}`, nil, decodeTarget{Author: "seneca", Quote: "q"}},
+		{"upper case", `{"AUTHOR":"seneca","quote":"q"}`, nil, decodeTarget{Author: "seneca", Quote: "q"}},
+		{"case variants are duplicates", `{"author":"seneca","Author":"nero","quote":"q"}`, []models.FieldError{
+			{Field: "Author", Message: "duplicate field"},
+		}, decodeTarget{Author: "seneca", Quote: "q"}},
+		{"unknown field", `{"Author":"seneca","Text":"q"}`, []models.FieldError{
+			{Field: "Text", Message: "unknown field"},
+		}, decodeTarget{Author: "seneca"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(tt.body))
+			var dst decodeTarget
+			errs, err := decodeLegacyJSON(httptest.NewRecorder(), r, 1<<10, &dst)
+			if err != nil {
+				t.Fatalf("unexpected body error: %v", err)
+			}
+			if !slices.Equal(errs, tt.want) {
+				t.Fatalf("errors = %v, want %v", errs, tt.want)
+			}
+			if dst != tt.dst {
+				t.Errorf("decoded %+v, want %+v", dst, tt.dst)
+			}
+		})
+	}
+}
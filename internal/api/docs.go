@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/api/openapi"
+)
+
+func (a *API) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openapi.Spec); err != nil {
+		a.logWriteError(r, err)
+	}
+}
+
+func (a *API) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write(openapi.DocsPage); err != nil {
+		a.logWriteError(r, err)
+	}
+}
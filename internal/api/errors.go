@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem — тело ответа об ошибке в формате RFC 9457.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Ошибки валидации по полям
+	Errors models.ValidationErrors `json:"errors,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(w, r, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	p.RequestID = requestid.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+// quoteETag строится из идентификатора, версии строки и числа отметок
+// «нравится»: отметка не меняет версию, но меняет представление.
+func quoteETag(q models.Quote) string {
+	return fmt.Sprintf(`"q%d-v%d-l%d"`, q.Id, q.Version, q.Likes)
+}
+
+// trimLikes убирает из ETag цитаты число отметок. If-Match защищает от
+// потерянных изменений текста, и чужая отметка не должна ему мешать.
+func trimLikes(tag string) string {
+	i := strings.LastIndex(tag, "-l")
+	if i < 0 || !strings.HasSuffix(tag, `"`) {
+		return tag
+	}
+	for _, c := range tag[i+2 : len(tag)-1] {
+		if c < '0' || c > '9' {
+			return tag
+		}
+	}
+	return tag[:i] + `"`
+}
+
+// collectionETag строится из счётчика изменений коллекции и представления:
+// пути (версии API) и параметров запроса.
+func collectionETag(r *http.Request, version int64) string {
+	sum := sha256.Sum256([]byte(r.URL.Path + "?" + r.URL.Query().Encode()))
+	return fmt.Sprintf(`"c%d-%x"`, version, sum[:6])
+}
+
+// setETag выставляет ETag и просит клиентов перепроверять закэшированный ответ.
+func setETag(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+}
+
+// notModified отвечает 304, если If-None-Match совпадает с etag (слабое
+// сравнение, RFC 9110 13.1.2).
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range splitETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			setETag(w, etag)
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		// Клиент хранит сжатое представление: отвечаем его же тегом
+		if tag = strings.TrimPrefix(tag, "W/"); trimEncodingSuffix(tag) == etag {
+			setETag(w, tag)
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch проверяет If-Match (строгое сравнение, RFC 9110 13.1.1) без
+// учёта числа отметок. Отсутствие заголовка считается совпадением.
+func ifMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+
+	for _, tag := range splitETags(header) {
+		if tag == "*" || trimLikes(trimEncodingSuffix(tag)) == trimLikes(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitETags(header string) []string {
+	parts := strings.Split(header, ",")
+	tags := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			tags = append(tags, p)
+		}
+	}
+	return tags
+}
+
+// trimEncodingSuffix убирает суффикс кодировки, который CompressionMiddleware
+// добавляет к ETag сжатого ответа.
+func trimEncodingSuffix(tag string) string {
+	for _, enc := range []string{EncodingGzip, EncodingBrotli, EncodingZstd} {
+		if t, ok := strings.CutSuffix(tag, "-"+enc+`"`); ok {
+			return t + `"`
+		}
+	}
+	return tag
+}
+
+// collectionNotModified выставляет ETag коллекции и отвечает 304, если
+// клиент уже получил эту версию. Если счётчик изменений прочитать не
+// удалось, ответ отдаётся без ETag.
+func (a *API) collectionNotModified(w http.ResponseWriter, r *http.Request) bool {
+	version, err := a.service.CollectionVersion(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context(), a.logger).Warn("serving collection without etag", logger.Error(err))
+		return false
+	}
+
+	etag := collectionETag(r, version)
+	if notModified(w, r, etag) {
+		return true
+	}
+	setETag(w, etag)
+	return false
+}
+
+const preconditionFailed = "quote has been modified, fetch it again and retry"
+
+func writePreconditionFailed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusPreconditionFailed, preconditionFailed)
+}
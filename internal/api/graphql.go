@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/api/graphqlapi"
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// GraphQL выполняет запрос GraphQL. Ошибки самого запроса GraphQL
+// возвращаются с кодом 200 в поле errors, а тело, которое не удалось
+// разобрать как JSON, — обычной ошибкой API.
+func (a *API) GraphQL(w http.ResponseWriter, r *http.Request) {
+	var req graphqlapi.Request
+
+	verrs, err := decodeJSON(w, r, a.maxBodyBytes, &req)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		verrs = verrs.Merge(models.ValidationErrors{{Field: "query", Message: "must not be empty"}})
+	}
+	if len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, a.graphql.Execute(r.Context(), req))
+}
@@ -0,0 +1,59 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// Коды ошибок в extensions.code ответа GraphQL.
+const (
+	codeBadUserInput    = "BAD_USER_INPUT"
+	codeNotFound        = "NOT_FOUND"
+	codeAlreadyExists   = "ALREADY_EXISTS"
+	codeVersionMismatch = "VERSION_MISMATCH"
+	codeLimitExceeded   = "QUERY_LIMIT_EXCEEDED"
+	codeInternal        = "INTERNAL_SERVER_ERROR"
+)
+
+// resolverError — ошибка с кодом в extensions, по которому клиент
+// отличает её без разбора текста.
+type resolverError struct {
+	message string
+	code    string
+	fields  models.ValidationErrors
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	ext := map[string]any{"code": e.code}
+	if len(e.fields) > 0 {
+		ext["errors"] = e.fields
+	}
+	return ext
+}
+
+func badUserInput(errs models.ValidationErrors) error {
+	return &resolverError{message: errs.Error(), code: codeBadUserInput, fields: errs}
+}
+
+// serviceError переводит ошибку сервисного слоя в ошибку GraphQL.
+// Подробности неожиданных ошибок уже залогированы сервисом.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrQuoteNotFound):
+		return &resolverError{message: "quote not found", code: codeNotFound}
+	case errors.Is(err, models.ErrQuoteExists):
+		return &resolverError{message: "quote already exists", code: codeAlreadyExists}
+	case errors.Is(err, models.ErrVersionMismatch):
+		return &resolverError{message: "quote version mismatch", code: codeVersionMismatch}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &resolverError{message: "request timed out", code: codeInternal}
+	default:
+		return &resolverError{message: "internal server error", code: codeInternal}
+	}
+}
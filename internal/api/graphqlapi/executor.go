@@ -0,0 +1,77 @@
+package graphqlapi
+
+import (
+	"context"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request — тело запроса GraphQL over HTTP.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    map[string]any `json:"extensions"`
+}
+
+// Executor разбирает, проверяет и выполняет запросы GraphQL.
+type Executor struct {
+	schema  graphql.Schema
+	service interfaces.Service
+	cfg     config.GraphQLConfig
+}
+
+func NewExecutor(service interfaces.Service, cfg *config.GraphQLConfig) (*Executor, error) {
+	schema, err := newSchema(&resolver{service: service})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{schema: schema, service: service, cfg: *cfg}, nil
+}
+
+// Execute выполняет запрос. Ошибки разбора, валидации и превышения
+// ограничений возвращаются в поле errors результата, как того ждут
+// клиенты GraphQL.
+func (e *Executor) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if res := graphql.ValidateDocument(&e.schema, doc, nil); !res.IsValid {
+		return &graphql.Result{Errors: res.Errors}
+	}
+
+	l := limits{
+		maxDepth:      e.cfg.MaxDepth,
+		maxComplexity: e.cfg.MaxComplexity,
+		variables:     req.Variables,
+	}
+	if err := l.check(doc, req.OperationName); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{limitError(err)}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withAuthorCounts(ctx, e.service),
+	})
+}
+
+func limitError(err error) gqlerrors.FormattedError {
+	formatted := gqlerrors.FormatError(err)
+	if ext, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = ext.Extensions()
+	}
+	return formatted
+}
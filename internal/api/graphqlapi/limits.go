@@ -0,0 +1,159 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Поля-списки: стоимость их подзапроса умножается на first
+var connectionFields = map[string]bool{"quotes": true}
+
+// limits считает глубину и сложность операции до выполнения, чтобы один
+// запрос не мог заставить сервер выбрать из хранилища несоразмерно много.
+// Каждое поле стоит 1, подзапрос поля-списка умножается на first.
+// Служебные поля интроспекции ("__schema", "__typename") не учитываются:
+// их вложенность ограничена самой схемой.
+type limits struct {
+	maxDepth      int
+	maxComplexity int
+	fragments     map[string]*ast.FragmentDefinition
+	variables     map[string]any
+	defaults      map[string]ast.Value
+}
+
+// check возвращает ошибку, если операция превышает ограничения.
+func (l *limits) check(doc *ast.Document, operationName string) error {
+	var op *ast.OperationDefinition
+	l.fragments = make(map[string]*ast.FragmentDefinition)
+
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			l.fragments[def.Name.Value] = def
+		}
+	}
+	// Неизвестную операцию отклонит само выполнение
+	if op == nil {
+		return nil
+	}
+
+	l.defaults = make(map[string]ast.Value)
+	for _, vd := range op.VariableDefinitions {
+		if vd.DefaultValue != nil {
+			l.defaults[vd.Variable.Name.Value] = vd.DefaultValue
+		}
+	}
+
+	if depth := l.depth(op.SelectionSet); depth > l.maxDepth {
+		return &resolverError{
+			message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, l.maxDepth),
+			code:    codeLimitExceeded,
+		}
+	}
+	if complexity := l.complexity(op.SelectionSet); complexity > l.maxComplexity {
+		return &resolverError{
+			message: fmt.Sprintf("query complexity exceeds the limit of %d", l.maxComplexity),
+			code:    codeLimitExceeded,
+		}
+	}
+	return nil
+}
+
+func (l *limits) depth(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	deepest := 0
+	for _, sel := range l.fields(set) {
+		if strings.HasPrefix(sel.Name.Value, "__") {
+			continue
+		}
+		deepest = max(deepest, 1+l.depth(sel.SelectionSet))
+	}
+	return deepest
+}
+
+// complexity не растёт выше maxComplexity+1, чтобы произведение first
+// вложенных списков не переполнилось.
+func (l *limits) complexity(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	ceiling := l.maxComplexity + 1
+	total := 0
+	for _, field := range l.fields(set) {
+		if strings.HasPrefix(field.Name.Value, "__") {
+			continue
+		}
+
+		children := l.complexity(field.SelectionSet)
+		if connectionFields[field.Name.Value] {
+			children = min(children*l.first(field), ceiling)
+		}
+		total = min(total+1+children, ceiling)
+	}
+	return total
+}
+
+// fields раскрывает фрагменты и возвращает поля набора. Циклы фрагментов
+// уже отклонены валидацией документа.
+func (l *limits) fields(set *ast.SelectionSet) []*ast.Field {
+	var fields []*ast.Field
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			fields = append(fields, sel)
+		case *ast.InlineFragment:
+			if sel.SelectionSet != nil {
+				fields = append(fields, l.fields(sel.SelectionSet)...)
+			}
+		case *ast.FragmentSpread:
+			if frag, ok := l.fragments[sel.Name.Value]; ok && frag.SelectionSet != nil {
+				fields = append(fields, l.fields(frag.SelectionSet)...)
+			}
+		}
+	}
+	return fields
+}
+
+// first возвращает размер страницы поля-списка с учётом переменных.
+func (l *limits) first(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		value := arg.Value
+		if v, ok := value.(*ast.Variable); ok {
+			name := v.Name.Value
+			switch n := l.variables[name].(type) {
+			case float64:
+				return clampFirst(int(n))
+			case int:
+				return clampFirst(n)
+			}
+			value = l.defaults[name]
+		}
+		if v, ok := value.(*ast.IntValue); ok {
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return clampFirst(n)
+			}
+		}
+	}
+	return models.DefaultPageLimit
+}
+
+// Недопустимый first отклонит резолвер, для оценки хватит границ
+func clampFirst(n int) int {
+	return max(1, min(n, models.MaxPageLimit))
+}
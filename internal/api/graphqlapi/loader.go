@@ -0,0 +1,76 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Grino777/quotes/internal/interfaces"
+)
+
+type authorCountsKey struct{}
+
+// authorCounts загружает число цитат авторов в пределах одного запроса.
+// Поле quoteCount возвращает отложенное значение, поэтому к моменту первой
+// загрузки известны все авторы текущего уровня ответа, и они считаются
+// одним запросом к хранилищу вместо запроса на каждую цитату.
+type authorCounts struct {
+	service interfaces.Service
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	counts  map[string]int
+	err     error
+}
+
+func withAuthorCounts(ctx context.Context, service interfaces.Service) context.Context {
+	return context.WithValue(ctx, authorCountsKey{}, &authorCounts{
+		service: service,
+		pending: make(map[string]struct{}),
+		counts:  make(map[string]int),
+	})
+}
+
+func authorCountsFrom(ctx context.Context) *authorCounts {
+	return ctx.Value(authorCountsKey{}).(*authorCounts)
+}
+
+// load ставит автора в очередь и возвращает функцию, которую исполнитель
+// GraphQL вызывает после разрешения остальных полей уровня.
+func (l *authorCounts) load(ctx context.Context, name string) func() (any, error) {
+	l.mu.Lock()
+	if _, ok := l.counts[name]; !ok {
+		l.pending[name] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.pending[name]; ok {
+			l.flush(ctx)
+		}
+		if l.err != nil {
+			return nil, serviceError(l.err)
+		}
+		return l.counts[name], nil
+	}
+}
+
+// flush загружает всех ожидающих авторов. Вызывается под l.mu.
+func (l *authorCounts) flush(ctx context.Context) {
+	names := make([]string, 0, len(l.pending))
+	for name := range l.pending {
+		names = append(names, name)
+	}
+	clear(l.pending)
+
+	counts, err := l.service.CountQuotesByAuthor(ctx, names)
+	if err != nil {
+		l.err = err
+		return
+	}
+	for _, name := range names {
+		l.counts[name] = counts[name]
+	}
+}
@@ -0,0 +1,83 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+)
+
+// countingService отдаёт фиксированную страницу цитат и записывает вызовы
+// CountQuotesByAuthor. Остальные методы сервиса тесту не нужны.
+type countingService struct {
+	interfaces.Service
+	quotes []models.Quote
+	calls  [][]string
+}
+
+func (s *countingService) ListQuotes(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error) {
+	return s.quotes, len(s.quotes), nil
+}
+
+func (s *countingService) CountQuotesByAuthor(ctx context.Context, authors []string) (map[string]int, error) {
+	s.calls = append(s.calls, slices.Sorted(slices.Values(authors)))
+
+	counts := make(map[string]int)
+	for _, q := range s.quotes {
+		counts[q.Author]++
+	}
+	return counts, nil
+}
+
+func TestAuthorQuoteCountBatched(t *testing.T) {
+	service := &countingService{quotes: []models.Quote{
+		{Id: 1, Author: "seneca", Quote: "a"},
+		{Id: 2, Author: "epictetus", Quote: "b"},
+		{Id: 3, Author: "seneca", Quote: "c"},
+	}}
+	e, err := NewExecutor(service, &config.GraphQLConfig{MaxDepth: 10, MaxComplexity: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := e.Execute(context.Background(), Request{
+		Query: `{ quotes(first: 3) { edges { node { author { name quoteCount } } } } }`,
+	})
+	if len(res.Errors) > 0 {
+		t.Fatalf("errors: %v", res.Errors)
+	}
+
+	if want := [][]string{{"epictetus", "seneca"}}; !slices.EqualFunc(service.calls, want, slices.Equal) {
+		t.Fatalf("CountQuotesByAuthor calls = %v, want %v", service.calls, want)
+	}
+
+	raw, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data struct {
+		Quotes struct {
+			Edges []struct {
+				Node struct {
+					Author struct {
+						Name       string
+						QuoteCount int
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	for _, edge := range data.Quotes.Edges {
+		want := map[string]int{"seneca": 2, "epictetus": 1}[edge.Node.Author.Name]
+		if edge.Node.Author.QuoteCount != want {
+			t.Errorf("%s quoteCount = %d, want %d", edge.Node.Author.Name, edge.Node.Author.QuoteCount, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package graphqlapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/graphql-go/graphql"
+)
+
+// resolver вычисляет поля схемы через сервисный слой.
+type resolver struct {
+	service interfaces.Service
+}
+
+// authorProfile — источник для полей типа Author.
+type authorProfile struct {
+	name string
+}
+
+func (r *resolver) quote(p graphql.ResolveParams) (any, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := r.service.GetQuote(p.Context, id)
+	if err != nil {
+		// Отсутствующая цитата — null, а не ошибка запроса
+		if errors.Is(err, models.ErrQuoteNotFound) {
+			return nil, nil
+		}
+		return nil, serviceError(err)
+	}
+	return quote, nil
+}
+
+func (r *resolver) quotes(p graphql.ResolveParams) (any, error) {
+	filter, err := connectionFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	if author, ok := p.Args["author"].(string); ok {
+		filter.Author = strings.TrimSpace(author)
+	}
+	if search, ok := p.Args["search"].(string); ok {
+		search = strings.TrimSpace(search)
+		if utf8.RuneCountInString(search) > models.SearchMaxLength {
+			return nil, badUserInput(models.ValidationErrors{{Field: "search", Message: "must be at most 1000 characters"}})
+		}
+		filter.Query = search
+	}
+
+	return r.connection(p, filter)
+}
+
+func (r *resolver) randomQuote(p graphql.ResolveParams) (any, error) {
+	author, _ := p.Args["author"].(string)
+
+	quote, err := r.service.GetRandomQuote(p.Context, strings.TrimSpace(author))
+	if err != nil {
+		if errors.Is(err, models.ErrQuoteNotFound) {
+			return nil, nil
+		}
+		return nil, serviceError(err)
+	}
+	return quote, nil
+}
+
+// author возвращает профиль автора или null, если у него нет цитат.
+func (r *resolver) author(p graphql.ResolveParams) (any, error) {
+	name := strings.ToLower(strings.TrimSpace(p.Args["name"].(string)))
+	if name == "" {
+		return nil, nil
+	}
+
+	_, total, err := r.service.ListQuotes(p.Context, models.QuoteFilter{Author: name, Limit: 1})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	if total == 0 {
+		return nil, nil
+	}
+	return authorProfile{name: name}, nil
+}
+
+func (r *resolver) createQuote(p graphql.ResolveParams) (any, error) {
+	input := p.Args["input"].(map[string]any)
+
+	q := models.Quote{Author: input["author"].(string), Quote: input["quote"].(string)}
+	q.Normalize()
+	if err := q.Validate(); err != nil {
+		return nil, badUserInput(err.(models.ValidationErrors))
+	}
+
+	created, err := r.service.CreateQuote(p.Context, q)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return created, nil
+}
+
+func (r *resolver) deleteQuote(p graphql.ResolveParams) (any, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	version, _ := p.Args["version"].(int)
+	if version < 0 {
+		return nil, badUserInput(models.ValidationErrors{{Field: "version", Message: "must not be negative"}})
+	}
+
+	if err := r.service.DeleteQuote(p.Context, id, int64(version)); err != nil {
+		return nil, serviceError(err)
+	}
+	return true, nil
+}
+
+func (r *resolver) quoteID(p graphql.ResolveParams) (any, error) {
+	return strconv.Itoa(int(p.Source.(models.Quote).Id)), nil
+}
+
+func (r *resolver) quoteText(p graphql.ResolveParams) (any, error) {
+	return p.Source.(models.Quote).Quote, nil
+}
+
+func (r *resolver) quoteAuthor(p graphql.ResolveParams) (any, error) {
+	return authorProfile{name: p.Source.(models.Quote).Author}, nil
+}
+
+func (r *resolver) quoteVersion(p graphql.ResolveParams) (any, error) {
+	return p.Source.(models.Quote).Version, nil
+}
+
+func (r *resolver) quoteLikes(p graphql.ResolveParams) (any, error) {
+	return p.Source.(models.Quote).Likes, nil
+}
+
+func (r *resolver) quoteCreatedAt(p graphql.ResolveParams) (any, error) {
+	return p.Source.(models.Quote).CreatedAt, nil
+}
+
+func (r *resolver) quoteUpdatedAt(p graphql.ResolveParams) (any, error) {
+	return p.Source.(models.Quote).UpdatedAt, nil
+}
+
+func (r *resolver) authorName(p graphql.ResolveParams) (any, error) {
+	return p.Source.(authorProfile).name, nil
+}
+
+func (r *resolver) authorQuoteCount(p graphql.ResolveParams) (any, error) {
+	name := strings.ToLower(p.Source.(authorProfile).name)
+	return authorCountsFrom(p.Context).load(p.Context, name), nil
+}
+
+func (r *resolver) authorQuotes(p graphql.ResolveParams) (any, error) {
+	filter, err := connectionFilter(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter.Author = p.Source.(authorProfile).name
+
+	return r.connection(p, filter)
+}
+
+// connection выбирает страницу цитат и оформляет её как QuoteConnection.
+// Курсор элемента — его позиция в выдаче.
+func (r *resolver) connection(p graphql.ResolveParams, filter models.QuoteFilter) (any, error) {
+	quotes, total, err := r.service.ListQuotes(p.Context, filter)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	edges := make([]map[string]any, 0, len(quotes))
+	for i, q := range quotes {
+		edges = append(edges, map[string]any{
+			"cursor": encodeCursor(filter.Offset + i),
+			"node":   q,
+		})
+	}
+
+	pageInfo := map[string]any{
+		"hasNextPage":     filter.Offset+len(quotes) < total,
+		"hasPreviousPage": filter.Offset > 0,
+	}
+	if len(edges) > 0 {
+		pageInfo["startCursor"] = edges[0]["cursor"]
+		pageInfo["endCursor"] = edges[len(edges)-1]["cursor"]
+	}
+
+	return map[string]any{
+		"edges":      edges,
+		"pageInfo":   pageInfo,
+		"totalCount": total,
+	}, nil
+}
+
+// connectionFilter переводит аргументы first, after и sort в QuoteFilter.
+func connectionFilter(args map[string]any) (models.QuoteFilter, error) {
+	var errs models.ValidationErrors
+	filter := models.QuoteFilter{Limit: models.DefaultPageLimit}
+
+	if first, ok := args["first"].(int); ok {
+		if first < 1 || first > models.MaxPageLimit {
+			errs = append(errs, models.FieldError{
+				Field:   "first",
+				Message: fmt.Sprintf("must be between 1 and %d", models.MaxPageLimit),
+			})
+		}
+		filter.Limit = first
+	}
+
+	if after, ok := args["after"].(string); ok {
+		pos, err := decodeCursor(after)
+		if err != nil {
+			errs = append(errs, models.FieldError{Field: "after", Message: "is not a valid cursor"})
+		}
+		filter.Offset = pos + 1
+	}
+
+	if sort, ok := args["sort"].(models.QuoteSort); ok {
+		filter.Sort = sort
+	}
+
+	if len(errs) > 0 {
+		return models.QuoteFilter{}, badUserInput(errs)
+	}
+	return filter, nil
+}
+
+func parseID(v any) (int, error) {
+	s, _ := v.(string)
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, badUserInput(models.ValidationErrors{{Field: "id", Message: "must be a positive integer"}})
+	}
+	return id, nil
+}
+
+// Курсор непрозрачен для клиента, чтобы формат можно было сменить без
+// изменения схемы.
+func encodeCursor(pos int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("quote:" + strconv.Itoa(pos)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	pos, err := strconv.Atoi(strings.TrimPrefix(string(raw), "quote:"))
+	if err != nil || !strings.HasPrefix(string(raw), "quote:") || pos < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return pos, nil
+}
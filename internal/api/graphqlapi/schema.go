@@ -0,0 +1,169 @@
+package graphqlapi
+
+import (
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/graphql-go/graphql"
+)
+
+var quoteSortEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "QuoteSort",
+	Description: "Порядок выдачи списка, совпадает с параметром sort HTTP API.",
+	Values: graphql.EnumValueConfigMap{
+		"CREATED_AT":      {Value: models.SortCreatedAt},
+		"CREATED_AT_DESC": {Value: models.SortCreatedAtDesc},
+		"AUTHOR":          {Value: models.SortAuthor},
+		"AUTHOR_DESC":     {Value: models.SortAuthorDesc},
+		"LENGTH":          {Value: models.SortLength},
+		"LENGTH_DESC":     {Value: models.SortLengthDesc},
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage":     {Type: graphql.NewNonNull(graphql.Boolean)},
+		"hasPreviousPage": {Type: graphql.NewNonNull(graphql.Boolean)},
+		"startCursor":     {Type: graphql.String},
+		"endCursor":       {Type: graphql.String},
+	},
+})
+
+var createQuoteInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "CreateQuoteInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"author": {Type: graphql.NewNonNull(graphql.String)},
+		"quote":  {Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+// connectionArgs — аргументы полей-списков в стиле Relay: first и курсор
+// after последнего полученного элемента.
+func connectionArgs(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first": {Type: graphql.Int, DefaultValue: models.DefaultPageLimit},
+		"after": {Type: graphql.String},
+		"sort":  {Type: quoteSortEnum},
+	}
+	for name, arg := range extra {
+		args[name] = arg
+	}
+	return args
+}
+
+// newSchema собирает схему. Типы Quote и Author ссылаются друг на друга,
+// поэтому поля Author задаются отложенно.
+func newSchema(r *resolver) (graphql.Schema, error) {
+	var connectionType *graphql.Object
+
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Author",
+		Description: "Автор цитат. Отдельного профиля автора в хранилище нет, он собирается по его цитатам.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"name":       {Type: graphql.NewNonNull(graphql.String), Resolve: r.authorName},
+				"quoteCount": {Type: graphql.NewNonNull(graphql.Int), Resolve: r.authorQuoteCount},
+				"quotes": {
+					Type:    graphql.NewNonNull(connectionType),
+					Args:    connectionArgs(nil),
+					Resolve: r.authorQuotes,
+				},
+			}
+		}),
+	})
+
+	quoteType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Quote",
+		Description: "Цитата. Тегов у цитат нет: хранилище их не поддерживает, поэтому поля tags в схеме нет.",
+		Fields: graphql.Fields{
+			"id":     {Type: graphql.NewNonNull(graphql.ID), Resolve: r.quoteID},
+			"quote":  {Type: graphql.NewNonNull(graphql.String), Resolve: r.quoteText},
+			"author": {Type: graphql.NewNonNull(authorType), Resolve: r.quoteAuthor},
+			"version": {
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Версия строки для условного удаления.",
+				Resolve:     r.quoteVersion,
+			},
+			"likes": {
+				Type:        graphql.NewNonNull(graphql.Int),
+				Description: "Число отметок «нравится».",
+				Resolve:     r.quoteLikes,
+			},
+			"createdAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: r.quoteCreatedAt},
+			"updatedAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: r.quoteUpdatedAt},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "QuoteEdge",
+		Fields: graphql.Fields{
+			"cursor": {Type: graphql.NewNonNull(graphql.String)},
+			"node":   {Type: graphql.NewNonNull(quoteType)},
+		},
+	})
+
+	connectionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "QuoteConnection",
+		Fields: graphql.Fields{
+			"edges":      {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo":   {Type: graphql.NewNonNull(pageInfoType)},
+			"totalCount": {Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"quote": {
+				Type:    quoteType,
+				Args:    graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: r.quote,
+			},
+			"quotes": {
+				Type: graphql.NewNonNull(connectionType),
+				Args: connectionArgs(graphql.FieldConfigArgument{
+					"author": {Type: graphql.String},
+					"search": {
+						Type:        graphql.String,
+						Description: "Подстрока текста или автора, регистр латиницы не учитывается.",
+					},
+				}),
+				Resolve: r.quotes,
+			},
+			"randomQuote": {
+				Type:    quoteType,
+				Args:    graphql.FieldConfigArgument{"author": {Type: graphql.String}},
+				Resolve: r.randomQuote,
+			},
+			"author": {
+				Type:    authorType,
+				Args:    graphql.FieldConfigArgument{"name": {Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: r.author,
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createQuote": {
+				Type:    graphql.NewNonNull(quoteType),
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(createQuoteInput)}},
+				Resolve: r.createQuote,
+			},
+			"deleteQuote": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Переносит цитату в корзину. С version удаление условное, как с If-Match.",
+				Args: graphql.FieldConfigArgument{
+					"id":      {Type: graphql.NewNonNull(graphql.ID)},
+					"version": {Type: graphql.Int},
+				},
+				Resolve: r.deleteQuote,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
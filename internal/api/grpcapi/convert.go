@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	quotesv1 "github.com/Grino777/quotes/proto/quotes/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var sorts = map[quotesv1.QuoteSort]models.QuoteSort{
+	quotesv1.QuoteSort_QUOTE_SORT_UNSPECIFIED:     models.SortDefault,
+	quotesv1.QuoteSort_QUOTE_SORT_CREATED_AT:      models.SortCreatedAt,
+	quotesv1.QuoteSort_QUOTE_SORT_CREATED_AT_DESC: models.SortCreatedAtDesc,
+	quotesv1.QuoteSort_QUOTE_SORT_AUTHOR:          models.SortAuthor,
+	quotesv1.QuoteSort_QUOTE_SORT_AUTHOR_DESC:     models.SortAuthorDesc,
+	quotesv1.QuoteSort_QUOTE_SORT_LENGTH:          models.SortLength,
+	quotesv1.QuoteSort_QUOTE_SORT_LENGTH_DESC:     models.SortLengthDesc,
+}
+
+var eventTypes = map[string]quotesv1.EventType{
+	models.EventQuoteCreated:  quotesv1.EventType_EVENT_TYPE_CREATED,
+	models.EventQuoteUpdated:  quotesv1.EventType_EVENT_TYPE_UPDATED,
+	models.EventQuoteDeleted:  quotesv1.EventType_EVENT_TYPE_DELETED,
+	models.EventQuoteRestored: quotesv1.EventType_EVENT_TYPE_RESTORED,
+}
+
+func toQuote(q models.Quote) *quotesv1.Quote {
+	return &quotesv1.Quote{
+		Id:        q.Id,
+		Author:    q.Author,
+		Quote:     q.Quote,
+		Version:   q.Version,
+		Likes:     q.Likes,
+		CreatedAt: timestamppb.New(q.CreatedAt),
+		UpdatedAt: timestamppb.New(q.UpdatedAt),
+	}
+}
+
+func toEvent(e models.QuoteEvent) *quotesv1.QuoteEvent {
+	return &quotesv1.QuoteEvent{
+		Id:      e.ID,
+		Type:    eventTypes[e.Type],
+		QuoteId: e.QuoteID,
+		Author:  e.Quote.Author,
+		Quote:   e.Quote.Quote,
+		Actor:   e.Actor,
+		Time:    timestamppb.New(e.Time),
+	}
+}
+
+// pageFilter переводит параметры постраничной выдачи в QuoteFilter.
+// Ошибка уже имеет код InvalidArgument.
+func pageFilter(pageSize int32, pageToken string, sort quotesv1.QuoteSort) (models.QuoteFilter, error) {
+	var errs models.ValidationErrors
+	filter := models.QuoteFilter{Limit: int(pageSize)}
+
+	switch {
+	case pageSize == 0:
+		filter.Limit = models.DefaultPageLimit
+	case pageSize < 0 || pageSize > models.MaxPageLimit:
+		errs = append(errs, models.FieldError{
+			Field:   "page_size",
+			Message: fmt.Sprintf("must be between 1 and %d", models.MaxPageLimit),
+		})
+	}
+
+	if pageToken != "" {
+		offset, err := decodePageToken(pageToken)
+		if err != nil {
+			errs = append(errs, models.FieldError{Field: "page_token", Message: "is invalid"})
+		}
+		filter.Offset = offset
+	}
+
+	var ok bool
+	if filter.Sort, ok = sorts[sort]; !ok {
+		errs = append(errs, models.FieldError{Field: "sort", Message: "is not a known QuoteSort value"})
+	}
+
+	if len(errs) > 0 {
+		return models.QuoteFilter{}, invalidArgument(errs)
+	}
+	return filter, nil
+}
+
+// Токен страницы непрозрачен для клиента, чтобы формат можно было сменить
+// без изменения API. Сейчас это смещение в base64.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	return offset, nil
+}
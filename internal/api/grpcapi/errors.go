@@ -0,0 +1,49 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const internalError = "internal server error"
+
+// serviceError переводит ошибку сервисного слоя в статус gRPC. Подробности
+// неожиданных ошибок уже залогированы сервисом и клиенту не отдаются.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrQuoteNotFound):
+		return status.Error(codes.NotFound, "quote not found")
+	case errors.Is(err, models.ErrQuoteExists):
+		return status.Error(codes.AlreadyExists, "quote already exists")
+	case errors.Is(err, models.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, "quote version mismatch")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, internalError)
+	}
+}
+
+// invalidArgument возвращает InvalidArgument с проблемами полей в деталях
+// BadRequest.
+func invalidArgument(errs models.ValidationErrors) error {
+	st := status.New(codes.InvalidArgument, errs.Error())
+
+	details := &errdetails.BadRequest{}
+	for _, fe := range errs {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field,
+			Description: fe.Message,
+		})
+	}
+
+	if withDetails, err := st.WithDetails(details); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
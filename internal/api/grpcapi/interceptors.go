@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/auth"
+	"github.com/Grino777/quotes/internal/lib/clientip"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/metrics"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "github.com/Grino777/quotes/internal/api/grpcapi"
+
+// Ключи метаданных gRPC всегда в нижнем регистре
+var (
+	requestIDKey = strings.ToLower(requestid.Header)
+	actorKey     = strings.ToLower(actor.Header)
+	authKey      = strings.ToLower(auth.Header)
+)
+
+// UnaryInterceptor готовит контекст вызова так же, как middleware HTTP API,
+// и логирует результат. Вызов с неверным ключом API отклоняется с
+// Unauthenticated.
+func UnaryInterceptor(log *slog.Logger, keys *auth.Keys) grpc.UnaryServerInterceptor {
+	tracer := otel.Tracer(tracerName)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span, err := callContext(ctx, tracer, log, keys, info.FullMethod)
+		defer span.End()
+
+		start := time.Now()
+		var resp any
+		if err == nil {
+			resp, err = handler(ctx, req)
+		}
+		finishCall(ctx, log, span, err, start)
+		return resp, err
+	}
+}
+
+// StreamInterceptor — то же для потоковых вызовов.
+func StreamInterceptor(log *slog.Logger, keys *auth.Keys) grpc.StreamServerInterceptor {
+	tracer := otel.Tracer(tracerName)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span, err := callContext(ss.Context(), tracer, log, keys, info.FullMethod)
+		defer span.End()
+
+		start := time.Now()
+		if err == nil {
+			err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		}
+		finishCall(ctx, log, span, err, start)
+		return err
+	}
+}
+
+// UnaryRecovery перехватывает панику обработчика и отвечает Internal
+// вместо падения процесса.
+func UnaryRecovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = reportPanic(logger.FromContext(ctx, log), v)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecovery(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = reportPanic(logger.FromContext(ss.Context(), log), v)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// callContext продолжает трассу из метаданных traceparent, открывает
+// серверный спан и кладёт в контекст идентификатор запроса, автора
+// изменений, IP клиента и логгер вызова. Автор определяется как в HTTP
+// API: владелец ключа из authorization, иначе неподтверждённый x-actor.
+// Ошибка означает неверный ключ; спан и контекст при этом всё равно готовы.
+func callContext(
+	ctx context.Context,
+	tracer trace.Tracer,
+	log *slog.Logger,
+	keys *auth.Keys,
+	method string,
+) (context.Context, trace.Span, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := first(md, requestIDKey)
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	var authErr error
+	name := first(md, actorKey)
+	if header := first(md, authKey); header != "" {
+		principal, ok := auth.Principal{}, false
+		if token, valid := auth.BearerToken(header); valid {
+			principal, ok = keys.Lookup(token)
+		}
+		if ok {
+			ctx = auth.WithPrincipal(ctx, principal)
+			name = principal.Subject
+		} else {
+			authErr = status.Error(codes.Unauthenticated, "invalid api key")
+			name = actor.Anonymous
+		}
+	} else if !actor.Valid(name) {
+		name = actor.Anonymous
+	}
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+		),
+	)
+
+	callLog := log.With(
+		slog.String("request_id", id),
+		slog.String("method", method),
+		slog.String("actor", name),
+	)
+	if sc := span.SpanContext(); sc.IsValid() {
+		callLog = callLog.With(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+
+	ctx = requestid.WithID(ctx, id)
+	ctx = actor.WithActor(ctx, name)
+	ctx = clientip.WithIP(ctx, peerIP(ctx))
+	ctx = logger.WithContext(ctx, callLog)
+	return ctx, span, authErr
+}
+
+func finishCall(ctx context.Context, log *slog.Logger, span trace.Span, err error, start time.Time) {
+	code := status.Code(err)
+
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unimplemented, codes.DataLoss:
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+
+	logger.FromContext(ctx, log).Debug("call executed",
+		slog.String("code", code.String()),
+		slog.Float64("exec_time_sec", time.Since(start).Seconds()),
+	)
+}
+
+func reportPanic(log *slog.Logger, v any) error {
+	metrics.GRPCPanics.Add(1)
+	log.Error("panic recovered",
+		slog.Any("panic", v),
+		slog.String("stack", string(debug.Stack())),
+	)
+	return status.Error(codes.Internal, internalError)
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// serverStream подменяет контекст потока контекстом вызова.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataCarrier позволяет пропагатору OpenTelemetry читать метаданные gRPC.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	return first(metadata.MD(c), key)
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
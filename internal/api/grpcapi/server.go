@@ -0,0 +1,209 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	quotesv1 "github.com/Grino777/quotes/proto/quotes/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Сколько событий читается из журнала за один запрос в WatchQuotes
+const watchBatch = 100
+
+// Server реализует QuotesService поверх того же сервисного слоя, что и
+// HTTP API.
+type Server struct {
+	quotesv1.UnimplementedQuotesServiceServer
+
+	logger  *slog.Logger
+	service interfaces.Service
+	events  interfaces.EventSubscriber
+}
+
+func NewServer(log *slog.Logger, service interfaces.Service, events interfaces.EventSubscriber) *Server {
+	return &Server{
+		logger:  log,
+		service: service,
+		events:  events,
+	}
+}
+
+func (s *Server) GetQuote(ctx context.Context, req *quotesv1.GetQuoteRequest) (*quotesv1.Quote, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid quote ID")
+	}
+
+	quote, err := s.service.GetQuote(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return toQuote(quote), nil
+}
+
+func (s *Server) ListQuotes(ctx context.Context, req *quotesv1.ListQuotesRequest) (*quotesv1.ListQuotesResponse, error) {
+	filter, err := pageFilter(req.GetPageSize(), req.GetPageToken(), req.GetSort())
+	if err != nil {
+		return nil, err
+	}
+	filter.Author = strings.TrimSpace(req.GetAuthor())
+
+	return s.listQuotes(ctx, filter)
+}
+
+func (s *Server) CreateQuote(ctx context.Context, req *quotesv1.CreateQuoteRequest) (*quotesv1.Quote, error) {
+	q := models.Quote{Author: req.GetAuthor(), Quote: req.GetQuote()}
+	q.Normalize()
+
+	if err := q.Validate(); err != nil {
+		return nil, invalidArgument(err.(models.ValidationErrors))
+	}
+
+	created, err := s.service.CreateQuote(ctx, q)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return toQuote(created), nil
+}
+
+// DeleteQuote переносит цитату в корзину. С version удаление условное, как
+// с If-Match в HTTP API.
+func (s *Server) DeleteQuote(ctx context.Context, req *quotesv1.DeleteQuoteRequest) (*quotesv1.DeleteQuoteResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid quote ID")
+	}
+	if req.GetVersion() < 0 {
+		return nil, invalidArgument(models.ValidationErrors{{Field: "version", Message: "must not be negative"}})
+	}
+
+	if err := s.service.DeleteQuote(ctx, int(req.GetId()), req.GetVersion()); err != nil {
+		return nil, serviceError(err)
+	}
+
+	return &quotesv1.DeleteQuoteResponse{}, nil
+}
+
+func (s *Server) RandomQuote(ctx context.Context, req *quotesv1.RandomQuoteRequest) (*quotesv1.Quote, error) {
+	quote, err := s.service.GetRandomQuote(ctx, strings.TrimSpace(req.GetAuthor()))
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	return toQuote(quote), nil
+}
+
+func (s *Server) SearchQuotes(ctx context.Context, req *quotesv1.SearchQuotesRequest) (*quotesv1.ListQuotesResponse, error) {
+	query := strings.TrimSpace(req.GetQuery())
+	switch {
+	case query == "":
+		return nil, invalidArgument(models.ValidationErrors{{Field: "query", Message: "must not be empty"}})
+	case utf8.RuneCountInString(query) > models.SearchMaxLength:
+		return nil, invalidArgument(models.ValidationErrors{{Field: "query", Message: "must be at most 1000 characters"}})
+	}
+
+	filter, err := pageFilter(req.GetPageSize(), req.GetPageToken(), req.GetSort())
+	if err != nil {
+		return nil, err
+	}
+	filter.Query = query
+
+	return s.listQuotes(ctx, filter)
+}
+
+func (s *Server) listQuotes(ctx context.Context, filter models.QuoteFilter) (*quotesv1.ListQuotesResponse, error) {
+	quotes, total, err := s.service.ListQuotes(ctx, filter)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+
+	resp := &quotesv1.ListQuotesResponse{
+		Quotes:    make([]*quotesv1.Quote, 0, len(quotes)),
+		TotalSize: int32(total),
+	}
+	for _, q := range quotes {
+		resp.Quotes = append(resp.Quotes, toQuote(q))
+	}
+	if next := filter.Offset + len(quotes); len(quotes) > 0 && next < total {
+		resp.NextPageToken = encodePageToken(next)
+	}
+
+	return resp, nil
+}
+
+// WatchQuotes отдаёт изменения цитат так же, как SSE-поток HTTP API: шина
+// событий лишь будит поток, сами события читаются из журнала изменений.
+// При остановке сервера поток завершается с Unavailable, и клиент может
+// переподключиться с последним полученным after_event_id.
+func (s *Server) WatchQuotes(req *quotesv1.WatchQuotesRequest, stream quotesv1.QuotesService_WatchQuotesServer) error {
+	ctx := stream.Context()
+	log := logger.FromContext(ctx, s.logger)
+
+	// Подписываемся до чтения журнала, чтобы не потерять события между ними
+	events, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	var lastID int64
+	if req.AfterEventId != nil {
+		if lastID = req.GetAfterEventId(); lastID < 0 {
+			return invalidArgument(models.ValidationErrors{{Field: "after_event_id", Message: "must not be negative"}})
+		}
+	} else {
+		var err error
+		if lastID, err = s.service.LastEventID(ctx); err != nil {
+			return serviceError(err)
+		}
+	}
+
+	var err error
+	if lastID, err = s.sendEventsSince(stream, lastID); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-events:
+			if !ok {
+				log.Debug("event stream closed by server shutdown")
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			if event.ID <= lastID {
+				continue
+			}
+			if lastID, err = s.sendEventsSince(stream, lastID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendEventsSince отправляет все события журнала после lastID и возвращает
+// идентификатор последнего отправленного.
+func (s *Server) sendEventsSince(stream quotesv1.QuotesService_WatchQuotesServer, lastID int64) (int64, error) {
+	for {
+		events, err := s.service.QuoteEvents(stream.Context(), lastID, watchBatch)
+		if err != nil {
+			return lastID, serviceError(err)
+		}
+
+		for _, event := range events {
+			if err := stream.Send(toEvent(event)); err != nil {
+				return lastID, err
+			}
+			lastID = event.ID
+		}
+
+		if len(events) < watchBatch {
+			return lastID, nil
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+const readinessTimeout = 2 * time.Second
+
+// Healthz сообщает, что процесс жив и способен обслуживать запросы.
+func (a *API) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeStatus(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz сообщает, готов ли сервис принимать трафик: база доступна,
+// миграции применены и остановка приложения ещё не началась.
+func (a *API) Readyz(w http.ResponseWriter, r *http.Request) {
+	if a.shuttingDown.Load() {
+		writeStatus(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := a.service.Ping(ctx); err != nil {
+		a.logger.Warn("readiness check failed", logger.Error(err))
+		writeStatus(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+		return
+	}
+
+	writeStatus(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// SetShuttingDown переводит readiness в состояние отказа, чтобы балансировщик
+// перестал направлять новые запросы до остановки сервера.
+func (a *API) SetShuttingDown() {
+	a.shuttingDown.Store(true)
+}
+
+func writeStatus(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// QuoteHistory возвращает ревизии цитаты, новые первыми, в конверте API v2.
+func (a *API) QuoteHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, total, err := a.service.QuoteHistory(r.Context(), id, page.Limit, page.Offset)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, revisions, page.Limit, page.Offset, total))
+}
+
+// RevertQuote возвращает цитате содержимое ревизии. If-Match работает как
+// в UpdateQuoteV2.
+func (a *API) RevertQuote(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	rev, err := strconv.ParseInt(r.PathValue("rev"), 10, 64)
+	if err != nil || rev <= 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid revision")
+		return
+	}
+
+	version, ok := a.matchedVersion(w, r, id)
+	if !ok {
+		return
+	}
+
+	reverted, err := a.service.RevertQuote(r.Context(), id, rev, version)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, quoteETag(reverted))
+	a.writeJSON(w, r, http.StatusOK, newItem(r, reverted))
+}
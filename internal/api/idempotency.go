@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// Выставляется на ответах, повторённых из сохранённых
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Сохранение ответа повторяется с нарастающей паузой: база может быть
+// кратковременно заблокирована другой записью.
+const (
+	idempotencySaveAttempts = 3
+	idempotencySaveBackoff  = 50 * time.Millisecond
+)
+
+// Заголовки ответа, которые сохраняются вместе с телом для повтора.
+var idempotentHeaders = []string{"Content-Type", "X-Content-Type-Options", "Location", "ETag"}
+
+// Idempotent выполняет запрос с заголовком Idempotency-Key один раз: ответ
+// сохраняется на api.idempotency.ttl и возвращается на повторы с тем же
+// ключом и тем же телом. Ключ с другим телом или путём отклоняется с 422,
+// повтор до завершения первого запроса — с 409, пока не истёк таймаут
+// первого запроса. Ответы 5xx не сохраняются, чтобы запрос можно было
+// повторить. Ключи у каждого автора изменений свои.
+func (a *API) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			writeError(w, r, http.StatusBadRequest, fmt.Sprintf(
+				"%s must be 1 to %d visible ASCII characters", IdempotencyKeyHeader, models.IdempotencyKeyMaxLength))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, a.maxBodyBytes))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(w, r, http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("request body must not exceed %d bytes", a.maxBodyBytes))
+				return
+			}
+			writeError(w, r, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := a.service.BeginIdempotent(r.Context(), key, requestHash(r, body), a.idempotencyTTL, a.idempotencyLease(r))
+		switch {
+		case errors.Is(err, models.ErrIdempotencyKeyReused):
+			writeError(w, r, http.StatusUnprocessableEntity,
+				IdempotencyKeyHeader+" has already been used with a different request")
+			return
+		case errors.Is(err, models.ErrIdempotencyInProgress):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, r, http.StatusConflict,
+				"a request with this "+IdempotencyKeyHeader+" is still being processed")
+			return
+		case err != nil:
+			writeError(w, r, http.StatusInternalServerError, InternalError)
+			return
+		}
+
+		if stored != nil {
+			h := w.Header()
+			for name, value := range stored.Header {
+				h.Set(name, value)
+			}
+			h.Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(stored.Status)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		// Ответ сохраняется и после таймаута или разрыва соединения: запрос
+		// уже выполнен, и повтор должен получить его результат
+		ctx := context.WithoutCancel(r.Context())
+		rec := &idempotencyRecorder{ResponseWriter: w}
+		// Ключ освобождается только после 5xx или паники обработчика
+		release := true
+		defer func() {
+			if release {
+				if err := a.service.ReleaseIdempotent(ctx, key); err != nil {
+					logger.FromContext(ctx, a.logger).Error("failed to release idempotency key", logger.Error(err))
+				}
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		// Запрос выполнен: освобождённый ключ позволил бы выполнить его повторно
+		release = false
+
+		resp := models.IdempotentResponse{Status: rec.status, Header: make(map[string]string), Body: rec.body.Bytes()}
+		for _, name := range idempotentHeaders {
+			if value := w.Header().Get(name); value != "" {
+				resp.Header[name] = value
+			}
+		}
+		a.saveIdempotent(ctx, key, resp)
+	})
+}
+
+// saveIdempotent сохраняет ответ, повторяя попытку при ошибке. Если ответ
+// так и не сохранился, ключ остаётся арендованным до конца аренды.
+func (a *API) saveIdempotent(ctx context.Context, key string, resp models.IdempotentResponse) {
+	for attempt := 1; ; attempt++ {
+		err := a.service.SaveIdempotent(ctx, key, resp)
+		if err == nil {
+			return
+		}
+		if attempt == idempotencySaveAttempts {
+			logger.FromContext(ctx, a.logger).Error("failed to save idempotent response", logger.Error(err))
+			return
+		}
+		time.Sleep(time.Duration(attempt) * idempotencySaveBackoff)
+	}
+}
+
+// idempotencyLease возвращает срок аренды ключа — время до дедлайна
+// запроса от TimeoutMiddleware: после него запрос уже отменён, и ключ без
+// ответа остался от упавшего процесса. Без таймаута ключ занят до ttl.
+func (a *API) idempotencyLease(r *http.Request) time.Duration {
+	if deadline, ok := r.Context().Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return a.idempotencyTTL
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > models.IdempotencyKeyMaxLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash — отпечаток запроса, с которым связывается ключ.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyRecorder копирует статус и тело ответа для сохранения.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (ir *idempotencyRecorder) WriteHeader(status int) {
+	if ir.status == 0 {
+		ir.status = status
+	}
+	ir.ResponseWriter.WriteHeader(status)
+}
+
+func (ir *idempotencyRecorder) Write(b []byte) (int, error) {
+	if ir.status == 0 {
+		ir.status = http.StatusOK
+	}
+	ir.body.Write(b)
+	return ir.ResponseWriter.Write(b)
+}
+
+func (ir *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return ir.ResponseWriter
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+)
+
+// flakyIdempotency не сохраняет ответ первые failSaves раз.
+type flakyIdempotency struct {
+	interfaces.Service
+	failSaves int
+	saves     int
+	saved     *models.IdempotentResponse
+	released  bool
+}
+
+func (s *flakyIdempotency) BeginIdempotent(context.Context, string, string, time.Duration, time.Duration) (*models.IdempotentResponse, error) {
+	return nil, nil
+}
+
+func (s *flakyIdempotency) SaveIdempotent(_ context.Context, _ string, resp models.IdempotentResponse) error {
+	s.saves++
+	if s.saves <= s.failSaves {
+		return errors.New("database is locked")
+	}
+	s.saved = &resp
+	return nil
+}
+
+func (s *flakyIdempotency) ReleaseIdempotent(context.Context, string) error {
+	s.released = true
+	return nil
+}
+
+func TestIdempotentKeepsKeyAfterSuccess(t *testing.T) {
+	tests := []struct {
+		name         string
+		failSaves    int
+		status       int
+		wantSaved    bool
+		wantReleased bool
+	}{
+		{"saved", 0, http.StatusCreated, true, false},
+		{"save retried", idempotencySaveAttempts - 1, http.StatusCreated, true, false},
+		{"save failed", idempotencySaveAttempts, http.StatusCreated, false, false},
+		{"client error saved", 0, http.StatusConflict, true, false},
+		{"server error", 0, http.StatusInternalServerError, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &flakyIdempotency{failSaves: tt.failSaves}
+			a := &API{
+				logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+				service:        service,
+				maxBodyBytes:   1 << 20,
+				idempotencyTTL: time.Hour,
+			}
+			h := a.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{}`))
+			r.Header.Set(IdempotencyKeyHeader, "key-1")
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if (service.saved != nil) != tt.wantSaved {
+				t.Errorf("saved = %v, want %v", service.saved != nil, tt.wantSaved)
+			}
+			if service.released != tt.wantReleased {
+				t.Errorf("released = %v, want %v", service.released, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestIdempotentReleasesKeyOnPanic(t *testing.T) {
+	service := &flakyIdempotency{}
+	a := &API{
+		logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		service:        service,
+		maxBodyBytes:   1 << 20,
+		idempotencyTTL: time.Hour,
+	}
+	h := RecoveryMiddleware(a.logger, a.Idempotent(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})))
+
+	r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{}`))
+	r.Header.Set(IdempotencyKeyHeader, "key-1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !service.released || service.saved != nil {
+		t.Errorf("released = %v, saved = %v, want released key without response", service.released, service.saved != nil)
+	}
+}
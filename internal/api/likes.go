@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/lib/auth"
+)
+
+// LikeQuote ставит цитате отметку «нравится» от владельца ключа API.
+// Повторная отметка ничего не меняет.
+func (a *API) LikeQuote(w http.ResponseWriter, r *http.Request) {
+	a.changeLike(w, r, true)
+}
+
+// UnlikeQuote снимает отметку владельца ключа API, если она была.
+func (a *API) UnlikeQuote(w http.ResponseWriter, r *http.Request) {
+	a.changeLike(w, r, false)
+}
+
+func (a *API) changeLike(w http.ResponseWriter, r *http.Request, like bool) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	// Маршрут закрыт RequireKey: X-Actor не проверяется и для отметок не годится
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		writeKeyRequired(w, r)
+		return
+	}
+
+	change := a.service.UnlikeQuote
+	if like {
+		change = a.service.LikeQuote
+	}
+
+	quote, err := change(r.Context(), id, principal.Subject)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, quoteETag(quote))
+	a.writeJSON(w, r, http.StatusOK, newItem(r, quote))
+}
+
+// PopularQuotes возвращает отмеченные цитаты, самые популярные первыми.
+// Свежие отметки весят больше старых, см. api.popular.half_life.
+func (a *API) PopularQuotes(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseQuoteFilter(w, r)
+	if !ok {
+		return
+	}
+
+	quotes, total, err := a.service.PopularQuotes(r.Context(), a.popularHalfLife, filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, quotes, filter.Limit, filter.Offset, total))
+}
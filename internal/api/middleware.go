@@ -1,10 +1,15 @@
 package api
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/auth"
+	"github.com/Grino777/quotes/internal/lib/clientip"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/requestid"
 )
 
 func ApplyMiddlewares(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
@@ -14,41 +19,70 @@ func ApplyMiddlewares(handler http.Handler, middlewares ...func(http.Handler) ht
 	return handler
 }
 
-func LoggingMiddleware(log *slog.Logger, next http.Handler) http.Handler {
+// RequestIDMiddleware принимает X-Request-ID клиента или генерирует новый,
+// возвращает его в ответе и кладёт в контекст логгер запроса.
+func RequestIDMiddleware(log *slog.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
 
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		execTime := time.Since(start).Seconds()
-		log.Debug("request executed",
+		reqLog := log.With(
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
 			slog.String("path", r.URL.Path),
-			slog.Float64("exec_time_sec", execTime),
 		)
+
+		ctx := requestid.WithID(r.Context(), id)
+		ctx = logger.WithContext(ctx, reqLog)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ActorMiddleware определяет автора изменений. У запроса с ключом API это
+// владелец ключа, и X-Actor игнорируется. У анонимного запроса автор
+// берётся из X-Actor: заголовок ничем не подтверждён и служит только
+// подсказкой, без него действия записываются от имени anonymous.
+func ActorMiddleware(log *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Header.Get(actor.Header)
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			name = principal.Subject
+		} else if !actor.Valid(name) {
+			name = actor.Anonymous
+		}
+
+		ctx := actor.WithActor(r.Context(), name)
+		reqLog := logger.FromContext(ctx, log).With(slog.String("actor", name))
+		ctx = logger.WithContext(ctx, reqLog)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+// ClientIPMiddleware кладёт IP клиента в контекст для журнала аудита.
+func ClientIPMiddleware(trustProxy bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx, cancel := context.WithTimeout(r.Context(), timeout)
-			defer cancel()
-			r = r.WithContext(ctx)
-			done := make(chan struct{})
-			var respErr error
-			go func() {
-				defer close(done)
-				next.ServeHTTP(w, r)
-			}()
-			select {
-			case <-done:
-				return
-			case <-ctx.Done():
-				respErr = ctx.Err()
-				if respErr == context.DeadlineExceeded {
-					http.Error(w, "internal server error", http.StatusInternalServerError)
-				}
-			}
+			ctx := clientip.WithIP(r.Context(), clientip.FromRequest(r, trustProxy))
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+func LoggingMiddleware(log *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		rec := wrapRecorder(w)
+
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		execTime := time.Since(start).Seconds()
+		logger.FromContext(r.Context(), log).Debug("request executed",
+			slog.Int("status", rec.Status()),
+			slog.Float64("exec_time_sec", execTime),
+		)
+	})
+}
@@ -0,0 +1,52 @@
+// Package openapi содержит спецификацию OpenAPI 3.1 и встроенную страницу документации.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//go:embed openapi.json
+var Spec []byte
+
+//go:embed docs.html
+var DocsPage []byte
+
+type document struct {
+	Paths map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Missing возвращает шаблоны маршрутов ServeMux ("GET /quotes/{id}"),
+// для которых в спецификации нет операции.
+func Missing(patterns []string) ([]string, error) {
+	var doc document
+	if err := json.Unmarshal(Spec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi spec: %w", err)
+	}
+
+	var missing []string
+	for _, pattern := range patterns {
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok {
+			// Маршрут без метода должен быть описан хотя бы одной операцией
+			method, path = "", pattern
+		}
+
+		ops, found := doc.Paths[path]
+		if !found {
+			missing = append(missing, pattern)
+			continue
+		}
+		if method != "" {
+			if _, found := ops[strings.ToLower(method)]; !found {
+				missing = append(missing, pattern)
+			}
+		}
+	}
+
+	sort.Strings(missing)
+	return missing, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/metrics"
+)
+
+// handlerPanic переносит панику из горутины обработчика вместе со стеком
+// в момент паники, чтобы при повторном panic стек не потерялся.
+type handlerPanic struct {
+	value any
+	stack []byte
+}
+
+func newHandlerPanic(v any) *handlerPanic {
+	if p, ok := v.(*handlerPanic); ok {
+		return p
+	}
+	return &handlerPanic{value: v, stack: debug.Stack()}
+}
+
+func (p *handlerPanic) String() string {
+	return fmt.Sprint(p.value)
+}
+
+// RecoveryMiddleware перехватывает панику обработчика, логирует её со стеком
+// и отвечает 500 вместо падения процесса.
+func RecoveryMiddleware(log *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := wrapRecorder(w)
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// ErrAbortHandler — штатный способ оборвать ответ, net/http обработает
+			// его сам, даже если паника пришла обёрнутой в handlerPanic
+			p := newHandlerPanic(v)
+			if p.value == http.ErrAbortHandler {
+				panic(http.ErrAbortHandler)
+			}
+
+			reportPanic(logger.FromContext(r.Context(), log), p)
+
+			if rec.status == 0 {
+				writeError(rec, r, http.StatusInternalServerError, InternalError)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+func reportPanic(log *slog.Logger, p *handlerPanic) {
+	metrics.HTTPPanics.Add(1)
+	log.Error("panic recovered",
+		slog.String("panic", p.String()),
+		slog.String("stack", string(p.stack)),
+	)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecoveryRepanicsAbortHandler(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	abort := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	for name, h := range map[string]http.Handler{
+		"direct":       RecoveryMiddleware(log, abort),
+		"with timeout": RecoveryMiddleware(log, TimeoutMiddleware(time.Second)(abort)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			defer func() {
+				if v := recover(); v != http.ErrAbortHandler {
+					t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+				}
+				if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+					t.Fatalf("response written: %d %q", rec.Code, rec.Body.String())
+				}
+			}()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	}
+}
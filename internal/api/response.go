@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+)
+
+// Конверты ответов API v2. Одиночный объект и коллекция всегда лежат в
+// поле data, коллекция — всегда массив, даже пустой.
+type itemEnvelope struct {
+	Data any  `json:"data"`
+	Meta meta `json:"meta"`
+}
+
+type collectionEnvelope struct {
+	Data       any        `json:"data"`
+	Pagination pagination `json:"pagination"`
+	Meta       meta       `json:"meta"`
+}
+
+type pagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+}
+
+type meta struct {
+	APIVersion string `json:"api_version"`
+	RequestID  string `json:"request_id,omitempty"`
+}
+
+func newMeta(r *http.Request) meta {
+	return meta{APIVersion: "v2", RequestID: requestid.FromContext(r.Context())}
+}
+
+func newItem(r *http.Request, data any) itemEnvelope {
+	return itemEnvelope{Data: data, Meta: newMeta(r)}
+}
+
+func newCollection(r *http.Request, data any, limit, offset, total int) collectionEnvelope {
+	return collectionEnvelope{
+		Data: data,
+		Pagination: pagination{
+			Limit:   limit,
+			Offset:  offset,
+			Total:   total,
+			HasMore: offset+limit < total,
+		},
+		Meta: newMeta(r),
+	}
+}
+
+func (a *API) writeJSON(w http.ResponseWriter, r *http.Request, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		logger.FromContext(r.Context(), a.logger).Error("failed to marshaling data", logger.Error(err))
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		a.logWriteError(r, err)
+	}
+}
+
+// quoteID разбирает идентификатор цитаты из пути запроса.
+func quoteID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt разбирает целочисленный параметр запроса, def — значение по умолчанию.
+func queryInt(r *http.Request, name string, def int) (int, *models.FieldError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &models.FieldError{Field: name, Message: "must be an integer"}
+	}
+	return v, nil
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+// Типы сообщений протокола ротации цитат
+const (
+	rotationSubscribe    = "subscribe"
+	rotationUnsubscribe  = "unsubscribe"
+	rotationSubscribed   = "subscribed"
+	rotationUnsubscribed = "unsubscribed"
+	rotationQuote        = "quote"
+	rotationError        = "error"
+)
+
+const (
+	// Максимальный размер сообщения клиента
+	rotationReadLimit = 4096
+	// Сколько ждать записи сообщения и ответа на ping
+	rotationWriteTimeout = 10 * time.Second
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// rotationRequest — сообщение клиента. Interval задаётся в секундах.
+type rotationRequest struct {
+	Type     string `json:"type"`
+	Interval int    `json:"interval,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+}
+
+// rotationMessage — сообщение сервера.
+type rotationMessage struct {
+	Type     string        `json:"type"`
+	Interval int           `json:"interval,omitempty"`
+	Author   string        `json:"author,omitempty"`
+	Quote    *models.Quote `json:"quote,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// rotation — состояние одного соединения.
+type rotation struct {
+	api    *API
+	conn   *websocket.Conn
+	log    *slog.Logger
+	limit  *tokenBucket
+	ticker *time.Ticker
+	author string
+	// Подряд идущие сообщения сверх лимита
+	violations int
+	// Последняя выборка была пустой, повторять ошибку на каждом тике незачем
+	missing bool
+}
+
+// QuoteRotation по WebSocket присылает клиенту случайную цитату с выбранным
+// им интервалом. Клиент управляет подпиской сообщениями subscribe
+// (interval в секундах, необязательный author) и unsubscribe; повторный
+// subscribe заменяет подписку. Сообщения клиента ограничены по частоте,
+// при остановке сервера соединение закрывается с кодом 1001.
+func (a *API) QuoteRotation(w http.ResponseWriter, r *http.Request) {
+	a.sockets.Add(1)
+	defer a.sockets.Done()
+
+	log := logger.FromContext(r.Context(), a.logger)
+
+	select {
+	case <-a.closing:
+		writeError(w, r, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	default:
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: a.originHosts})
+	if err != nil {
+		// Accept уже ответил клиенту
+		log.Debug("websocket handshake failed", logger.Error(err))
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(rotationReadLimit)
+
+	// После перехвата соединения контекст запроса ненадёжен, берём из него
+	// только значения: логгер, актора, идентификатор запроса
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+
+	rt := &rotation{
+		api:   a,
+		conn:  conn,
+		log:   log,
+		limit: newTokenBucket(a.ws.MessageRate, a.ws.MessageBurst),
+	}
+	defer rt.stop()
+
+	reqs := make(chan rotationRequest)
+	readErr := make(chan error, 1)
+	go rt.read(ctx, reqs, readErr)
+
+	ping := time.NewTicker(a.ws.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-a.closing:
+			log.Debug("websocket closed by server shutdown")
+			_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+			return
+		case err := <-readErr:
+			if status := websocket.CloseStatus(err); status == -1 && !errors.Is(err, context.Canceled) {
+				log.Debug("websocket read failed", logger.Error(err))
+			}
+			return
+		case req := <-reqs:
+			if !rt.limit.Allow() {
+				if err := rt.reject(ctx); err != nil {
+					return
+				}
+				continue
+			}
+			rt.violations = 0
+			if err := rt.handle(ctx, req); err != nil {
+				return
+			}
+		case <-rt.tick():
+			if err := rt.sendQuote(ctx); err != nil {
+				return
+			}
+		case <-ping.C:
+			pingCtx, cancel := context.WithTimeout(ctx, rotationWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				log.Debug("websocket ping failed", logger.Error(err))
+				return
+			}
+		}
+	}
+}
+
+// CloseSockets закрывает все WebSocket-соединения и ждёт завершения их
+// обработчиков, но не дольше ctx. Перехваченные соединения не видны
+// http.Server.Shutdown, поэтому закрываются отдельно.
+func (a *API) CloseSockets(ctx context.Context) error {
+	a.closeOnce.Do(func() { close(a.closing) })
+
+	done := make(chan struct{})
+	go func() {
+		a.sockets.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// read передаёт сообщения клиента в основной цикл. Сообщения с неверным
+// JSON не рвут соединение, клиент получает ошибку.
+func (rt *rotation) read(ctx context.Context, reqs chan<- rotationRequest, errs chan<- error) {
+	for {
+		typ, data, err := rt.conn.Read(ctx)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		// Нераспознанное сообщение остаётся с пустым типом
+		var req rotationRequest
+		if typ != websocket.MessageText || json.Unmarshal(data, &req) != nil {
+			req = rotationRequest{}
+		}
+
+		select {
+		case reqs <- req:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reject отбрасывает сообщение сверх лимита соединения. Клиент, долго
+// игнорирующий ошибки о превышении, отключается с кодом 1008.
+func (rt *rotation) reject(ctx context.Context) error {
+	rt.violations++
+	if rt.violations > rt.api.ws.MaxViolations {
+		rt.log.Debug("websocket closed for exceeding rate limit")
+		_ = rt.conn.Close(websocket.StatusPolicyViolation, "rate limit exceeded")
+		return errRateLimited
+	}
+	return rt.write(ctx, rotationMessage{Type: rotationError, Error: "rate limit exceeded"})
+}
+
+func (rt *rotation) handle(ctx context.Context, req rotationRequest) error {
+	switch req.Type {
+	case rotationSubscribe:
+		return rt.subscribe(ctx, req)
+	case rotationUnsubscribe:
+		rt.stop()
+		return rt.write(ctx, rotationMessage{Type: rotationUnsubscribed})
+	case "":
+		return rt.write(ctx, rotationMessage{Type: rotationError, Error: "invalid message"})
+	default:
+		return rt.write(ctx, rotationMessage{Type: rotationError, Error: "unknown message type " + req.Type})
+	}
+}
+
+// subscribe проверяет параметры подписки, сразу отправляет первую цитату
+// и запускает таймер.
+func (rt *rotation) subscribe(ctx context.Context, req rotationRequest) error {
+	cfg := rt.api.ws
+
+	interval := time.Duration(req.Interval) * time.Second
+	if req.Interval == 0 {
+		interval = cfg.DefaultInterval
+	}
+	if interval < cfg.MinInterval || interval > cfg.MaxInterval {
+		return rt.write(ctx, rotationMessage{
+			Type:  rotationError,
+			Error: "interval must be between " + cfg.MinInterval.String() + " and " + cfg.MaxInterval.String(),
+		})
+	}
+	// У цитат нет тегов, фильтр по ним не поддерживается
+	if req.Tag != "" {
+		return rt.write(ctx, rotationMessage{Type: rotationError, Error: "tag filter is not supported"})
+	}
+
+	rt.stop()
+	rt.author = strings.TrimSpace(req.Author)
+	rt.missing = false
+
+	err := rt.write(ctx, rotationMessage{
+		Type:     rotationSubscribed,
+		Interval: int(interval / time.Second),
+		Author:   rt.author,
+	})
+	if err != nil {
+		return err
+	}
+	if err := rt.sendQuote(ctx); err != nil {
+		return err
+	}
+
+	rt.ticker = time.NewTicker(interval)
+	return nil
+}
+
+// sendQuote отправляет случайную цитату подписки. Ошибки выборки
+// сообщаются клиенту, соединение при этом не закрывается.
+func (rt *rotation) sendQuote(ctx context.Context) error {
+	quote, err := rt.api.service.GetRandomQuote(ctx, rt.author)
+	if errors.Is(err, models.ErrQuoteNotFound) {
+		if rt.missing {
+			return nil
+		}
+		rt.missing = true
+		return rt.write(ctx, rotationMessage{Type: rotationError, Error: "no quotes match the subscription"})
+	}
+	if err != nil {
+		return rt.write(ctx, rotationMessage{Type: rotationError, Error: InternalError})
+	}
+
+	rt.missing = false
+	return rt.write(ctx, rotationMessage{Type: rotationQuote, Quote: &quote})
+}
+
+func (rt *rotation) write(ctx context.Context, msg rotationMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, rotationWriteTimeout)
+	defer cancel()
+
+	if err := wsjson.Write(ctx, rt.conn, msg); err != nil {
+		rt.log.Debug("websocket write failed", logger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// tick возвращает канал таймера подписки или nil, если подписки нет.
+func (rt *rotation) tick() <-chan time.Time {
+	if rt.ticker == nil {
+		return nil
+	}
+	return rt.ticker.C
+}
+
+func (rt *rotation) stop() {
+	if rt.ticker != nil {
+		rt.ticker.Stop()
+		rt.ticker = nil
+	}
+}
+
+// originHosts переводит разрешённые для CORS источники в шаблоны хостов,
+// которые проверяются при установке WebSocket-соединения.
+func originHosts(origins []string) []string {
+	hosts := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if _, host, ok := strings.Cut(origin, "://"); ok {
+			origin = host
+		}
+		hosts = append(hosts, origin)
+	}
+	return hosts
+}
+
+// tokenBucket — ограничитель частоты сообщений одного соединения. Доступ
+// только из основного цикла соединения, поэтому без блокировок.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (b *tokenBucket) Allow() bool {
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
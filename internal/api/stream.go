@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+const (
+	// Сколько событий читается из журнала за один запрос
+	streamBatch = 100
+	// Пауза перед переподключением, которую браузер берёт из поля retry
+	streamRetry = 3 * time.Second
+)
+
+// QuoteStream отдаёт изменения цитат как Server-Sent Events. Клиент,
+// приславший Last-Event-ID (или параметр last_event_id), сначала получает
+// пропущенные события из журнала изменений, остальные — только новые.
+// Шина событий лишь будит поток, сами события всегда читаются из журнала,
+// поэтому порядок и отсутствие пропусков не зависят от переполнения шины.
+func (a *API) QuoteStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx, a.logger)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	lastID, ok := lastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	// Подписываемся до чтения журнала, чтобы не потерять события между ними
+	events, unsubscribe := a.events.Subscribe()
+	defer unsubscribe()
+
+	if lastID < 0 {
+		var err error
+		if lastID, err = a.service.LastEventID(ctx); err != nil {
+			writeError(w, r, http.StatusInternalServerError, InternalError)
+			return
+		}
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+		return
+	}
+
+	var err error
+	if lastID, err = a.sendEventsSince(w, r, lastID); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(a.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				log.Debug("event stream closed by server shutdown")
+				return
+			}
+			if event.ID <= lastID {
+				continue
+			}
+			if lastID, err = a.sendEventsSince(w, r, lastID); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// sendEventsSince пишет все события журнала после lastID и возвращает
+// идентификатор последнего отправленного.
+func (a *API) sendEventsSince(w http.ResponseWriter, r *http.Request, lastID int64) (int64, error) {
+	for {
+		events, err := a.service.QuoteEvents(r.Context(), lastID, streamBatch)
+		if err != nil {
+			return lastID, err
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return lastID, err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return lastID, err
+			}
+			lastID = event.ID
+		}
+
+		if len(events) < streamBatch {
+			return lastID, nil
+		}
+	}
+}
+
+// lastEventID возвращает идентификатор, с которого продолжить поток, или -1,
+// если клиент подключается впервые. Если возвращено false, ответ с ошибкой
+// уже отправлен.
+func lastEventID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		// EventSource не умеет выставлять заголовки при первом подключении
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	if raw == "" {
+		return -1, true
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid Last-Event-ID")
+		return 0, false
+	}
+	return id, true
+}
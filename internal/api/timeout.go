@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+// TimeoutMiddleware ограничивает время обработки запроса. Ответ обработчика
+// копится в буфере и отправляется клиенту только если он успел до дедлайна,
+// поэтому после таймаута горутина обработчика больше не может писать в ответ.
+// При истечении дедлайна клиент получает 504, а при отмене запроса снаружи
+// (остановка сервера, разрыв соединения) — 503.
+// Нулевой timeout отключает middleware, например для потоковых ответов.
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parent := r.Context()
+			ctx, cancel := context.WithTimeout(parent, timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, h: make(http.Header)}
+			done := make(chan struct{})
+			panicChan := make(chan *handlerPanic)
+
+			go func() {
+				defer close(done)
+				// net/http не перехватывает паники в порождённых горутинах
+				defer func() {
+					if v := recover(); v != nil {
+						p := newHandlerPanic(v)
+						select {
+						case panicChan <- p:
+						case <-ctx.Done():
+							// Ответ уже отдан по таймауту, остаётся только залогировать
+							if p.value != http.ErrAbortHandler {
+								reportPanic(logger.FromContext(ctx, slog.Default()), p)
+							}
+						}
+					}
+				}()
+				next.ServeHTTP(tw, r)
+			}()
+
+			select {
+			case <-done:
+				tw.flushTo(w)
+			case p := <-panicChan:
+				if p.value == http.ErrAbortHandler {
+					panic(http.ErrAbortHandler)
+				}
+				// Передаём панику в RecoveryMiddleware в горутине сервера
+				panic(p)
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+
+				// Родительский контекст жив — значит, сработал именно наш дедлайн
+				if parent.Err() == nil {
+					writeError(w, r, http.StatusGatewayTimeout, "request timed out")
+					return
+				}
+				w.Header().Set("Retry-After", "1")
+				writeError(w, r, http.StatusServiceUnavailable, "request cancelled")
+			}
+		})
+	}
+}
+
+// timeoutWriter буферизует ответ обработчика. Все методы защищены мьютексом,
+// после таймаута запись возвращает http.ErrHandlerTimeout.
+type timeoutWriter struct {
+	w http.ResponseWriter
+	h http.Header
+
+	mu          sync.Mutex
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+func (tw *timeoutWriter) flushTo(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	dst := w.Header()
+	for k, vv := range tw.h {
+		dst[k] = vv
+	}
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	_, _ = w.Write(tw.buf.Bytes())
+}
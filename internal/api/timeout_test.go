@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutDropsLateWrites(t *testing.T) {
+	responded := make(chan struct{})
+	lateWrite := make(chan error, 1)
+	h := TimeoutMiddleware(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "slow")
+		_, _ = w.Write([]byte("partial"))
+		// Горутина обработчика пишет уже после ответа 504
+		<-responded
+		_, err := w.Write([]byte("late"))
+		lateWrite <- err
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+	close(responded)
+
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("late write error = %v, want http.ErrHandlerTimeout", err)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "partial") || strings.Contains(body, "late") {
+		t.Errorf("body mixes handler output into the timeout response: %q", body)
+	}
+	if rec.Header().Get("X-Handler") != "" {
+		t.Error("handler header leaked into the timeout response")
+	}
+	if rec.Header().Get("Content-Type") != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), problemContentType)
+	}
+}
+
+func TestTimeoutParentCancellation(t *testing.T) {
+	started := make(chan struct{})
+	responded := make(chan struct{})
+	finished := make(chan struct{})
+	h := TimeoutMiddleware(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(finished)
+		close(started)
+		<-r.Context().Done()
+		<-responded
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotes", nil).WithContext(ctx))
+	close(responded)
+	<-finished
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+	}
+}
+
+func TestTimeoutPanicReachesRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	h := RecoveryMiddleware(log, TimeoutMiddleware(time.Second)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom in handler")
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	// Стек снят в горутине обработчика, а не при повторной панике
+	if out := logs.String(); !strings.Contains(out, "boom in handler") || !strings.Contains(out, "TestTimeoutPanicReachesRecovery") {
+		t.Errorf("panic log lacks value or handler stack: %s", out)
+	}
+}
+
+func TestTimeoutKeepsHandlerHeaders(t *testing.T) {
+	h := TimeoutMiddleware(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"3"`)
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Origin")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-ID", "outer")
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/quotes", nil))
+
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"id":1}` {
+		t.Fatalf("response = %d %q, want 201 with handler body", rec.Code, rec.Body.String())
+	}
+	for name, want := range map[string]string{"Content-Type": "application/json", "ETag": `"3"`, "X-Request-ID": "outer"} {
+		if got := rec.Header().Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if vary := rec.Header().Values("Vary"); len(vary) != 2 {
+		t.Errorf("Vary = %q, want both values", vary)
+	}
+}
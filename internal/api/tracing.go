@@ -0,0 +1,63 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Grino777/quotes/internal/api"
+
+// TracingMiddleware продолжает трассу из заголовка traceparent (или начинает
+// новую), открывает серверный спан и добавляет trace_id/span_id в логгер запроса.
+func TracingMiddleware(log *slog.Logger, next http.Handler) http.Handler {
+	tracer := otel.Tracer(tracerName)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("user_agent.original", r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			reqLog := logger.FromContext(ctx, log).With(
+				slog.String("trace_id", sc.TraceID().String()),
+				slog.String("span_id", sc.SpanID().String()),
+			)
+			ctx = logger.WithContext(ctx, reqLog)
+		}
+
+		rec := wrapRecorder(w)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// RouteTracing называет серверный спан по шаблону маршрута, например "GET /quotes".
+func RouteTracing(pattern string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(pattern)
+			span.SetAttributes(attribute.String("http.route", pattern))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+)
+
+// ListTrash возвращает удалённые цитаты в конверте API v2 с полями
+// deleted_at и deleted_by.
+func (a *API) ListTrash(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseQuoteFilter(w, r)
+	if !ok {
+		return
+	}
+
+	if a.collectionNotModified(w, r) {
+		return
+	}
+
+	quotes, total, err := a.service.ListTrash(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, quotes, filter.Limit, filter.Offset, total))
+}
+
+// RestoreQuote возвращает цитату из корзины. Если за это время добавили
+// такую же цитату, отвечает 409.
+func (a *API) RestoreQuote(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	restored, err := a.service.RestoreQuote(r.Context(), id)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, quoteETag(restored))
+	a.writeJSON(w, r, http.StatusOK, newItem(r, restored))
+}
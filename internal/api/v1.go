@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+)
+
+// Обработчики API v1 сохраняют исходный формат ответов: пустые списки
+// как {}, ошибки предметной области со статусом 200 и полем error.
+
+// legacyQuote — цитата в формате v1 с полями Id/Author/Quote.
+type legacyQuote struct {
+	Id     int32
+	Author string
+	Quote  string
+}
+
+func toLegacy(q models.Quote) legacyQuote {
+	return legacyQuote{Id: q.Id, Author: q.Author, Quote: q.Quote}
+}
+
+func toLegacyList(quotes []models.Quote) []legacyQuote {
+	res := make([]legacyQuote, 0, len(quotes))
+	for _, q := range quotes {
+		res = append(res, toLegacy(q))
+	}
+	return res
+}
+
+func (a *API) AllQuotes(w http.ResponseWriter, r *http.Request) {
+	if a.collectionNotModified(w, r) {
+		return
+	}
+
+	sort := models.QuoteSort(r.URL.Query().Get("sort"))
+	if !sort.Valid() {
+		writeValidationError(w, r, models.ValidationErrors{models.InvalidSortError})
+		return
+	}
+
+	var (
+		quotes []models.Quote
+		err    error
+	)
+
+	author := r.URL.Query().Get("author")
+	if author != "" {
+		quotes, err = a.service.FilterQuotes(r.Context(), author, sort)
+	} else {
+		quotes, err = a.service.GetQuotes(r.Context(), sort)
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	if len(quotes) == 0 {
+		a.writeJSON(w, r, http.StatusOK, struct{}{})
+		return
+	}
+	a.writeJSON(w, r, http.StatusOK, toLegacyList(quotes))
+}
+
+func (a *API) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	q, ok := a.decodeQuote(w, r, decodeLegacyJSON)
+	if !ok {
+		return
+	}
+
+	created, err := a.service.CreateQuote(r.Context(), q)
+	if err != nil {
+		if errors.Is(err, models.ErrQuoteExists) {
+			a.writeJSON(w, r, http.StatusOK, map[string]string{
+				"error": "quote already exist", "request_id": requestid.FromContext(r.Context())})
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, map[string]any{"result": "success", "id": created.Id})
+}
+
+func (a *API) RandomQuote(w http.ResponseWriter, r *http.Request) {
+	quote, err := a.service.GetRandomQuote(r.Context(), "")
+	if err != nil {
+		if errors.Is(err, models.ErrQuoteNotFound) {
+			a.writeJSON(w, r, http.StatusOK, struct{}{})
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, toLegacy(quote))
+}
+
+func (a *API) DeleteQuote(w http.ResponseWriter, r *http.Request) {
+	// В v1 не числовой id — 400, а id <= 0 ищется как любой другой и не
+	// находится, поэтому quoteID здесь не подходит
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	notFound := func() {
+		a.writeJSON(w, r, http.StatusOK, map[string]string{
+			"error":      fmt.Sprintf("no quote found with id %d", id),
+			"request_id": requestid.FromContext(r.Context()),
+		})
+	}
+	if id <= 0 {
+		notFound()
+		return
+	}
+
+	// If-Match проверяется как в v2, но отсутствие цитаты остаётся ответом 200
+	version, err := a.ifMatchVersion(r, id)
+	if err == nil {
+		err = a.service.DeleteQuote(r.Context(), id, version)
+	}
+	switch {
+	case errors.Is(err, models.ErrQuoteNotFound):
+		notFound()
+		return
+	case errors.Is(err, models.ErrVersionMismatch):
+		writePreconditionFailed(w, r)
+		return
+	case err != nil:
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, map[string]string{
+		"result": fmt.Sprintf("quote with id: %d successfully deleted", id)})
+}
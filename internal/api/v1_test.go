@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+)
+
+// missingQuotes считает, что ни одной цитаты нет.
+type missingQuotes struct {
+	interfaces.Service
+	deleted []int
+}
+
+func (s *missingQuotes) DeleteQuote(ctx context.Context, id int, version int64) error {
+	s.deleted = append(s.deleted, id)
+	return models.ErrQuoteNotFound
+}
+
+// v1 отвечает на id <= 0 так же, как до появления v2: 200 и поле error.
+func TestDeleteQuoteV1KeepsLegacyStatus(t *testing.T) {
+	tests := []struct {
+		id         string
+		wantStatus int
+		wantError  string
+	}{
+		{"0", http.StatusOK, "no quote found with id 0"},
+		{"-3", http.StatusOK, "no quote found with id -3"},
+		{"7", http.StatusOK, "no quote found with id 7"},
+		{"abc", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			service := &missingQuotes{}
+			a := &API{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), service: service}
+
+			r := httptest.NewRequest(http.MethodDelete, "/quotes/"+tt.id, nil)
+			r.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			a.DeleteQuote(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantError == "" {
+				return
+			}
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body["error"] != tt.wantError {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+// versionedQuote хранит одну цитату с id 7 и версией 3.
+type versionedQuote struct {
+	interfaces.Service
+	deleted []int64
+}
+
+func (s *versionedQuote) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	if id != 7 {
+		return models.Quote{}, models.ErrQuoteNotFound
+	}
+	return models.Quote{Id: 7, Author: "seneca", Quote: "q", Version: 3}, nil
+}
+
+func (s *versionedQuote) DeleteQuote(ctx context.Context, id int, version int64) error {
+	if id != 7 {
+		return models.ErrQuoteNotFound
+	}
+	s.deleted = append(s.deleted, version)
+	return nil
+}
+
+func TestDeleteQuoteV1HonoursIfMatch(t *testing.T) {
+	current := quoteETag(models.Quote{Id: 7, Version: 3})
+	tests := []struct {
+		name        string
+		id          string
+		ifMatch     string
+		wantStatus  int
+		wantDeleted []int64
+	}{
+		{"unconditional", "7", "", http.StatusOK, []int64{0}},
+		{"matching etag", "7", current, http.StatusOK, []int64{3}},
+		{"any", "7", "*", http.StatusOK, []int64{3}},
+		{"stale etag", "7", `"q7-v2-l0"`, http.StatusPreconditionFailed, nil},
+		{"missing quote", "8", current, http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &versionedQuote{}
+			a := &API{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), service: service}
+
+			r := httptest.NewRequest(http.MethodDelete, "/quotes/"+tt.id, nil)
+			r.SetPathValue("id", tt.id)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			a.DeleteQuote(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !slices.Equal(service.deleted, tt.wantDeleted) {
+				t.Errorf("deleted with versions %v, want %v", service.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
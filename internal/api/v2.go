@@ -0,0 +1,231 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// Обработчики API v2: данные всегда в поле data, списки постраничные,
+// ошибки — problem+json с подходящим HTTP-статусом.
+
+func (a *API) ListQuotesV2(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseQuoteFilter(w, r)
+	if !ok {
+		return
+	}
+
+	if a.collectionNotModified(w, r) {
+		return
+	}
+
+	quotes, total, err := a.service.ListQuotes(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, quotes, filter.Limit, filter.Offset, total))
+}
+
+// parseQuoteFilter разбирает параметры author, sort, limit и offset. Если
+// возвращено false, ответ с ошибкой уже отправлен.
+func parseQuoteFilter(w http.ResponseWriter, r *http.Request) (models.QuoteFilter, bool) {
+	filter, verrs := queryPage(r)
+	filter.Author = r.URL.Query().Get("author")
+	filter.Sort = models.QuoteSort(r.URL.Query().Get("sort"))
+
+	if verrs = verrs.Merge(filter.Validate()); len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return models.QuoteFilter{}, false
+	}
+
+	return filter, true
+}
+
+// parsePage разбирает только limit и offset — для списков, которые не
+// фильтруются по автору и не сортируются.
+func parsePage(w http.ResponseWriter, r *http.Request) (models.QuoteFilter, bool) {
+	page, verrs := queryPage(r)
+
+	if verrs = verrs.Merge(page.Validate()); len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return models.QuoteFilter{}, false
+	}
+
+	return page, true
+}
+
+func queryPage(r *http.Request) (models.QuoteFilter, models.ValidationErrors) {
+	var verrs models.ValidationErrors
+
+	limit, ferr := queryInt(r, "limit", models.DefaultPageLimit)
+	if ferr != nil {
+		verrs = append(verrs, *ferr)
+	}
+	offset, ferr := queryInt(r, "offset", 0)
+	if ferr != nil {
+		verrs = append(verrs, *ferr)
+	}
+
+	return models.QuoteFilter{Limit: limit, Offset: offset}, verrs
+}
+
+func (a *API) GetQuoteV2(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	quote, err := a.service.GetQuote(r.Context(), id)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	etag := quoteETag(quote)
+	if notModified(w, r, etag) {
+		return
+	}
+
+	setETag(w, etag)
+	a.writeJSON(w, r, http.StatusOK, newItem(r, quote))
+}
+
+func (a *API) CreateQuoteV2(w http.ResponseWriter, r *http.Request) {
+	q, ok := a.decodeQuote(w, r, decodeJSON)
+	if !ok {
+		return
+	}
+
+	created, err := a.service.CreateQuote(r.Context(), q)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/v2/quotes/%d", created.Id))
+	setETag(w, quoteETag(created))
+	a.writeJSON(w, r, http.StatusCreated, newItem(r, created))
+}
+
+func (a *API) RandomQuoteV2(w http.ResponseWriter, r *http.Request) {
+	quote, err := a.service.GetRandomQuote(r.Context(), "")
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newItem(r, quote))
+}
+
+// UpdateQuoteV2 заменяет цитату. С заголовком If-Match изменение
+// применяется, только если цитата не менялась с момента чтения клиентом.
+func (a *API) UpdateQuoteV2(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	q, ok := a.decodeQuote(w, r, decodeJSON)
+	if !ok {
+		return
+	}
+
+	version, ok := a.matchedVersion(w, r, id)
+	if !ok {
+		return
+	}
+
+	updated, err := a.service.UpdateQuote(r.Context(), id, q, version)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	setETag(w, quoteETag(updated))
+	a.writeJSON(w, r, http.StatusOK, newItem(r, updated))
+}
+
+func (a *API) DeleteQuoteV2(w http.ResponseWriter, r *http.Request) {
+	id, ok := quoteID(r)
+	if !ok {
+		writeError(w, r, http.StatusBadRequest, "invalid quote ID")
+		return
+	}
+
+	version, ok := a.matchedVersion(w, r, id)
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteQuote(r.Context(), id, version); err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// matchedVersion проверяет If-Match и возвращает версию цитаты, которую
+// нужно передать в условное изменение. Без If-Match возвращается 0 —
+// изменение безусловное. Если возвращено false, ответ уже отправлен.
+func (a *API) matchedVersion(w http.ResponseWriter, r *http.Request, id int) (int64, bool) {
+	version, err := a.ifMatchVersion(r, id)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return 0, false
+	}
+	return version, true
+}
+
+// ifMatchVersion — matchedVersion без ответа клиенту: несовпадение ETag
+// возвращается как models.ErrVersionMismatch.
+func (a *API) ifMatchVersion(r *http.Request, id int) (int64, error) {
+	if r.Header.Get("If-Match") == "" {
+		return 0, nil
+	}
+
+	current, err := a.service.GetQuote(r.Context(), id)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ifMatch(r, quoteETag(current)) {
+		return 0, models.ErrVersionMismatch
+	}
+
+	return current.Version, nil
+}
+
+// writeServiceError переводит ошибки сервисного слоя в HTTP-статусы.
+func (a *API) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	status, detail := serviceErrorStatus(err)
+	writeError(w, r, status, detail)
+}
+
+// serviceErrorStatus возвращает HTTP-статус и описание ошибки сервисного слоя.
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrQuoteNotFound):
+		return http.StatusNotFound, "quote not found"
+	case errors.Is(err, models.ErrRevisionNotFound):
+		return http.StatusNotFound, "revision not found"
+	case errors.Is(err, models.ErrWebhookNotFound):
+		return http.StatusNotFound, "webhook not found"
+	case errors.Is(err, models.ErrDeliveryNotFound):
+		return http.StatusNotFound, "dead letter not found"
+	case errors.Is(err, models.ErrQuoteExists):
+		return http.StatusConflict, "quote already exists"
+	case errors.Is(err, models.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, preconditionFailed
+	case errors.Is(err, models.ErrBatchAborted):
+		return http.StatusFailedDependency, "another operation in the batch failed"
+	default:
+		return http.StatusInternalServerError, InternalError
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePageIgnoresFilterParams(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/quotes/1/history?author=seneca&sort=bogus&limit=5&offset=10", nil)
+	w := httptest.NewRecorder()
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		t.Fatalf("page rejected with %d: %s", w.Code, w.Body)
+	}
+	if page.Author != "" || page.Sort != "" || page.Limit != 5 || page.Offset != 10 {
+		t.Fatalf("page = %+v, want only limit 5 and offset 10", page)
+	}
+}
+
+func TestParsePageValidatesBounds(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1"} {
+		r := httptest.NewRequest(http.MethodGet, "/quotes/1/history?"+query, nil)
+		w := httptest.NewRecorder()
+
+		if _, ok := parsePage(w, r); ok {
+			t.Errorf("%s accepted", query)
+		} else if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want 422", query, w.Code)
+		}
+	}
+}
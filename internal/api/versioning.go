@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DeprecationMiddleware помечает ответы устаревшей версии API заголовками
+// Deprecation (RFC 9745), Sunset (RFC 8594) и ссылкой на маршрут в successor.
+// Нулевые даты не выставляют соответствующий заголовок.
+func DeprecationMiddleware(deprecatedAt, sunset time.Time, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			if !deprecatedAt.IsZero() {
+				h.Set("Deprecation", fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			}
+			if !sunset.IsZero() {
+				h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			path := strings.TrimPrefix(r.URL.Path, "/v1")
+			h.Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successor, path))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// webhookInput — тело запроса на создание подписки.
+type webhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// ListWebhooks возвращает все подписки без секретов.
+func (a *API) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := a.service.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, webhooks, len(webhooks), 0, len(webhooks)))
+}
+
+// CreateWebhook добавляет подписку. Секрет подписи возвращается только в
+// этом ответе.
+func (a *API) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var in webhookInput
+
+	verrs, err := decodeJSON(w, r, a.maxBodyBytes, &in)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	webhook := models.Webhook{
+		URL:    strings.TrimSpace(in.URL),
+		Events: in.Events,
+		Secret: in.Secret,
+	}
+	if verrs = verrs.Merge(webhook.Validate(a.webhookNetworks)); len(verrs) > 0 {
+		writeValidationError(w, r, verrs)
+		return
+	}
+
+	created, err := a.service.CreateWebhook(r.Context(), webhook)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/admin/webhooks/%d", created.ID))
+	a.writeJSON(w, r, http.StatusCreated, newItem(r, created))
+}
+
+func (a *API) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	webhook, err := a.service.GetWebhook(r.Context(), id)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newItem(r, webhook))
+}
+
+func (a *API) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteWebhook(r.Context(), id); err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookAttempts возвращает журнал доставки подписки, новые попытки
+// первыми.
+func (a *API) ListWebhookAttempts(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	attempts, total, err := a.service.ListWebhookAttempts(r.Context(), id, page.Limit, page.Offset)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, attempts, page.Limit, page.Offset, total))
+}
+
+// ListDeadDeliveries возвращает события, доставить которые не удалось за
+// все попытки.
+func (a *API) ListDeadDeliveries(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, total, err := a.service.ListDeadDeliveries(r.Context(), page.Limit, page.Offset)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, InternalError)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newCollection(r, deliveries, page.Limit, page.Offset, total))
+}
+
+// RetryDelivery возвращает недоставленное событие в очередь с новым
+// счётчиком попыток.
+func (a *API) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := a.service.RetryDelivery(r.Context(), id)
+	if err != nil {
+		a.writeServiceError(w, r, err)
+		return
+	}
+
+	a.writeJSON(w, r, http.StatusOK, newItem(r, delivery))
+}
+
+// pathID разбирает положительный идентификатор из пути. Если возвращено
+// false, ответ с ошибкой уже отправлен.
+func pathID(w http.ResponseWriter, r *http.Request, name, detail string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, r, http.StatusBadRequest, detail)
+		return 0, false
+	}
+	return id, true
+}
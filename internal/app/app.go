@@ -4,11 +4,18 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/Grino777/quotes/internal/app/server"
 	"github.com/Grino777/quotes/internal/config"
 	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/auth"
 	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	serviceAPI "github.com/Grino777/quotes/internal/services/api"
+	"github.com/Grino777/quotes/internal/services/audit"
+	"github.com/Grino777/quotes/internal/services/events"
+	"github.com/Grino777/quotes/internal/services/webhooks"
 	"github.com/Grino777/quotes/internal/storage/sqlite"
 	sqliteU "github.com/Grino777/quotes/internal/utils/sqlite"
 )
@@ -19,8 +26,18 @@ type App struct {
 	Logger    *slog.Logger
 	Config    *config.Config
 	ApiServer *server.APIServer
-	Storage   interfaces.Storage
-	cancel    context.CancelFunc
+	// nil, если gRPC отключён в конфигурации
+	GRPCServer *server.GRPCServer
+	Storage    interfaces.Storage
+	Service    interfaces.Service
+	Audit      *audit.Log
+	Events     *events.Bus
+	Webhooks   *webhooks.Dispatcher
+	cancel     context.CancelFunc
+	// Фоновые задачи, которые должны завершиться до закрытия хранилища
+	workers sync.WaitGroup
+	// Сбрасывает накопленные спаны и останавливает экспортёр трасс
+	shutdownTracing func(context.Context) error
 }
 
 func NewApp(log *slog.Logger) (*App, error) {
@@ -32,14 +49,58 @@ func NewApp(log *slog.Logger) (*App, error) {
 		return nil, err
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), &config.Tracing)
+	if err != nil {
+		log.Error("failed to setup tracing", slog.String("op", op), logger.Error(err))
+		return nil, err
+	}
+
 	storage := sqlite.NewStorage(log, &config.SQLite)
-	server := server.NewApiServer(log, &config.API, storage)
+	auditLog, err := audit.NewLog(log, config.Audit.File)
+	if err != nil {
+		log.Error("failed to create audit log", slog.String("op", op), logger.Error(err))
+		return nil, err
+	}
+	storage.OnAudit(auditLog.Mirror)
+
+	bus := events.NewBus()
+	service := serviceAPI.NewService(log, storage, bus)
+
+	keys, err := auth.NewKeys(config.API.Auth.Keys)
+	if err != nil {
+		log.Error("failed to load api keys", slog.String("op", op), logger.Error(err))
+		return nil, err
+	}
+
+	// Создаётся раньше API-сервера: переменная server скрывает имя пакета
+	var grpcServer *server.GRPCServer
+	if config.GRPC.Enabled {
+		grpcServer = server.NewGRPCServer(log, &config.GRPC, keys, service, bus)
+	}
+
+	server, err := server.NewApiServer(log, &config.API, config.Webhooks.AllowedNetworks, keys, service, bus)
+	if err != nil {
+		log.Error("failed to create api server", slog.String("op", op), logger.Error(err))
+		return nil, err
+	}
+
+	var dispatcher *webhooks.Dispatcher
+	if config.Webhooks.Enabled {
+		dispatcher = webhooks.NewDispatcher(log, storage, bus, &config.Webhooks)
+	}
 
 	return &App{
-		Logger:    log,
-		Config:    config,
-		ApiServer: server,
-		Storage:   storage,
+		Logger:     log,
+		Config:     config,
+		ApiServer:  server,
+		GRPCServer: grpcServer,
+		Storage:    storage,
+		Service:    service,
+		Audit:      auditLog,
+		Events:     bus,
+		Webhooks:   dispatcher,
+
+		shutdownTracing: shutdownTracing,
 	}, nil
 }
 
@@ -64,6 +125,14 @@ func (a *App) Run() error {
 		return err
 	}
 
+	if a.Webhooks != nil {
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			a.Webhooks.Run(ctx)
+		}()
+	}
+
 	errChan := make(chan error, 2)
 
 	wg.Add(1)
@@ -75,12 +144,25 @@ func (a *App) Run() error {
 		}
 	}()
 
+	if a.GRPCServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := a.GRPCServer.Run(ctx); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
 	select {
 	case <-ctx.Done():
 		log.Debug("app shutdown initiated")
 	case err := <-errChan:
 		log.Error("stopping app due to error", logger.Error(err))
 		lastErr = err
+		// Второй сервер не знает об ошибке первого
+		cancel()
 	}
 
 	wg.Wait()
@@ -89,7 +171,19 @@ func (a *App) Run() error {
 }
 
 func (a *App) Stop() error {
-	a.cancel()
+	if a.ApiServer != nil {
+		a.ApiServer.Drain()
+	}
+
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	// Потоки событий не завершатся сами, а Shutdown и GracefulStop ждут все
+	// активные запросы
+	if a.Events != nil {
+		a.Events.Close()
+	}
 
 	if a.ApiServer != nil {
 		if err := a.ApiServer.Stop(); err != nil {
@@ -97,12 +191,32 @@ func (a *App) Stop() error {
 		}
 	}
 
+	if a.GRPCServer != nil {
+		a.GRPCServer.Stop()
+	}
+
+	a.workers.Wait()
+
+	if a.Audit != nil {
+		if err := a.Audit.Close(); err != nil {
+			return err
+		}
+	}
+
 	if a.Storage != nil {
 		if err := a.Storage.Close(); err != nil {
 			return err
 		}
 	}
 
+	if a.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.shutdownTracing(ctx); err != nil {
+			return err
+		}
+	}
+
 	a.Logger.Debug("app successfully stopped")
 	return nil
 }
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Grino777/quotes/internal/api/grpcapi"
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/auth"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	quotesv1 "github.com/Grino777/quotes/proto/quotes/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCServer обслуживает gRPC API на отдельном порту.
+type GRPCServer struct {
+	server          *grpc.Server
+	logger          *slog.Logger
+	addr            string
+	shutdownTimeout time.Duration
+	stopOnce        sync.Once
+}
+
+func NewGRPCServer(
+	log *slog.Logger,
+	cfg *config.GRPCConfig,
+	keys *auth.Keys,
+	service interfaces.Service,
+	events interfaces.EventSubscriber,
+) *GRPCServer {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcapi.UnaryInterceptor(log, keys), grpcapi.UnaryRecovery(log)),
+		grpc.ChainStreamInterceptor(grpcapi.StreamInterceptor(log, keys), grpcapi.StreamRecovery(log)),
+	)
+	quotesv1.RegisterQuotesServiceServer(server, grpcapi.NewServer(log, service, events))
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
+
+	return &GRPCServer{
+		server:          server,
+		logger:          log,
+		addr:            fmt.Sprintf("%s:%s", cfg.Addr, cfg.Port),
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+func (gs *GRPCServer) Run(ctx context.Context) error {
+	const op = opServer + "GRPCServer.Run"
+
+	log := gs.logger.With("op", op)
+	log.Debug("starting grpc server", slog.String("addr", gs.addr))
+
+	listener, err := net.Listen("tcp", gs.addr)
+	if err != nil {
+		err = fmt.Errorf("%s: failed to listen: %w", op, err)
+		log.Error("grpc server stopped with error", logger.Error(err))
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		if err := gs.server.Serve(listener); err != nil {
+			errChan <- fmt.Errorf("%s: failed to serve: %w", op, err)
+		}
+	}()
+
+	log.Info("grpc server started successfully", slog.String("addr", listener.Addr().String()))
+
+	select {
+	case err := <-errChan:
+		log.Error("grpc server stopped with error", logger.Error(err))
+		return err
+	case <-ctx.Done():
+		log.Debug("grpc server shutdown initiated due to context cancellation")
+		gs.Stop()
+		return nil
+	}
+}
+
+// Stop ждёт завершения активных вызовов не дольше shutdown_timeout, после
+// чего обрывает оставшиеся. Потоки WatchQuotes завершаются сами при
+// закрытии шины событий, поэтому её нужно закрыть раньше.
+func (gs *GRPCServer) Stop() {
+	gs.stopOnce.Do(func() {
+		gs.logger.Debug("grpc server shutdown started")
+
+		done := make(chan struct{})
+		go func() {
+			gs.server.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(gs.shutdownTimeout):
+			gs.logger.Warn("grpc graceful shutdown timed out, closing remaining calls")
+			gs.server.Stop()
+			<-done
+		}
+
+		gs.logger.Debug("grpc server shutdown completed")
+	})
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/api/openapi"
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/lib/auth"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// Спецификация поддерживается вручную: каждый зарегистрированный маршрут
+// должен быть в ней описан.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	var cfg config.APIConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.AccessLog.Format = "off"
+
+	keys, err := auth.NewKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	as, err := NewApiServer(log, &cfg, nil, keys, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	as.setupMultiplexer()
+
+	if len(as.routes) == 0 {
+		t.Fatal("no routes registered")
+	}
+	missing, err := openapi.Missing(as.routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) > 0 {
+		t.Fatalf("routes missing from openapi spec: %v", missing)
+	}
+}
@@ -2,23 +2,103 @@ package server
 
 import (
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
 	"github.com/Grino777/quotes/internal/api"
+	"github.com/Grino777/quotes/internal/api/openapi"
 	"github.com/Grino777/quotes/internal/config"
 	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/auth"
 	"github.com/Grino777/quotes/internal/lib/logger"
-	serviceAPI "github.com/Grino777/quotes/internal/services/api"
 )
 
 const opServer = "app.server."
 
 type ApiProvider interface {
 	QuoteProvider
+	QuoteV2Provider
 	ApiRouter
+	HealthProvider
+	DocsProvider
+	TrashProvider
+	HistoryProvider
+	BatchProvider
+	AuditProvider
+	StreamProvider
+	SocketProvider
+	WebhookProvider
+	GraphQLProvider
+	IdempotencyProvider
+	LikeProvider
+}
+
+type LikeProvider interface {
+	LikeQuote(w http.ResponseWriter, r *http.Request)
+	UnlikeQuote(w http.ResponseWriter, r *http.Request)
+	PopularQuotes(w http.ResponseWriter, r *http.Request)
+}
+
+type IdempotencyProvider interface {
+	Idempotent(next http.Handler) http.Handler
+}
+
+type GraphQLProvider interface {
+	GraphQL(w http.ResponseWriter, r *http.Request)
+}
+
+type DocsProvider interface {
+	OpenAPISpec(w http.ResponseWriter, r *http.Request)
+	Docs(w http.ResponseWriter, r *http.Request)
+}
+
+type StreamProvider interface {
+	QuoteStream(w http.ResponseWriter, r *http.Request)
+}
+
+type SocketProvider interface {
+	QuoteRotation(w http.ResponseWriter, r *http.Request)
+	CloseSockets(ctx context.Context) error
+}
+
+type WebhookProvider interface {
+	ListWebhooks(w http.ResponseWriter, r *http.Request)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	ListWebhookAttempts(w http.ResponseWriter, r *http.Request)
+	ListDeadDeliveries(w http.ResponseWriter, r *http.Request)
+	RetryDelivery(w http.ResponseWriter, r *http.Request)
+}
+
+type AuditProvider interface {
+	ListAudit(w http.ResponseWriter, r *http.Request)
+}
+
+type HistoryProvider interface {
+	QuoteHistory(w http.ResponseWriter, r *http.Request)
+	RevertQuote(w http.ResponseWriter, r *http.Request)
+}
+
+type BatchProvider interface {
+	BatchOps(w http.ResponseWriter, r *http.Request)
+}
+
+type TrashProvider interface {
+	ListTrash(w http.ResponseWriter, r *http.Request)
+	RestoreQuote(w http.ResponseWriter, r *http.Request)
+}
+
+type HealthProvider interface {
+	Healthz(w http.ResponseWriter, r *http.Request)
+	Readyz(w http.ResponseWriter, r *http.Request)
+	SetShuttingDown()
 }
 
 type ApiRouter interface {
@@ -34,21 +114,74 @@ type QuoteProvider interface {
 	DeleteQuote(w http.ResponseWriter, r *http.Request)
 }
 
+type QuoteV2Provider interface {
+	ListQuotesV2(w http.ResponseWriter, r *http.Request)
+	GetQuoteV2(w http.ResponseWriter, r *http.Request)
+	CreateQuoteV2(w http.ResponseWriter, r *http.Request)
+	RandomQuoteV2(w http.ResponseWriter, r *http.Request)
+	UpdateQuoteV2(w http.ResponseWriter, r *http.Request)
+	DeleteQuoteV2(w http.ResponseWriter, r *http.Request)
+}
+
 type APIServer struct {
-	server *http.Server
-	logger *slog.Logger
-	api    ApiProvider
+	server    *http.Server
+	logger    *slog.Logger
+	api       ApiProvider
+	accessLog *api.AccessLogger
+	timeouts  config.TimeoutsConfig
+	compress  func(http.Handler) http.Handler
+	cors      *api.CORS
+	// Middleware с заголовками устаревания для маршрутов API v1
+	deprecated func(http.Handler) http.Handler
+	// Шаблоны зарегистрированных маршрутов, сверяются со спецификацией OpenAPI
+	routes     []string
+	drainDelay time.Duration
+	trustProxy bool
+	keys       *auth.Keys
 }
 
-func NewApiServer(log *slog.Logger, cfg *config.APIConfig, storage interfaces.Storage) *APIServer {
+func NewApiServer(
+	log *slog.Logger,
+	cfg *config.APIConfig,
+	webhookNetworks []netip.Prefix,
+	keys *auth.Keys,
+	service interfaces.Service,
+	events interfaces.EventSubscriber,
+) (*APIServer, error) {
+	const op = opServer + "NewApiServer"
+
 	addr := fmt.Sprintf("%s:%s", cfg.Addr, cfg.Port)
 
-	service := serviceAPI.NewService(log, storage)
-	apiInstance := api.NewApi(log, service)
+	accessLog, err := api.NewAccessLogger(&cfg.AccessLog, cfg.TrustProxyHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to create access log: %w", op, err)
+	}
+
+	deprecatedAt, sunset, err := cfg.Versioning.V1Dates()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	apiInstance, err := api.NewApi(log, service, events, cfg, webhookNetworks)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	server := &http.Server{Addr: addr}
 
-	return &APIServer{server: server, logger: log, api: apiInstance}
+	return &APIServer{
+		server:     server,
+		logger:     log,
+		api:        apiInstance,
+		accessLog:  accessLog,
+		timeouts:   cfg.Timeouts,
+		compress:   api.CompressionMiddleware(&cfg.Compression),
+		cors:       api.NewCORS(&cfg.CORS),
+		deprecated: api.DeprecationMiddleware(deprecatedAt, sunset, "/v2"),
+		drainDelay: cfg.DrainDelay,
+		trustProxy: cfg.TrustProxyHeaders,
+		keys:       keys,
+	}, nil
 }
 
 func (as *APIServer) Run(ctx context.Context) error {
@@ -59,21 +192,22 @@ func (as *APIServer) Run(ctx context.Context) error {
 	as.setupMultiplexer()
 	log.Debug("starting server", slog.String("addr", as.server.Addr))
 
+	// Слушаем порт синхронно, чтобы ошибка привязки была видна сразу
+	listener, err := net.Listen("tcp", as.server.Addr)
+	if err != nil {
+		err = fmt.Errorf("%s: failed to listen: %w", op, err)
+		log.Error("server stopped with error", logger.Error(err))
+		return err
+	}
+
 	errChan := make(chan error, 1)
 	go func() {
-		if err := as.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := as.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errChan <- fmt.Errorf("%s: failed to serve: %w", op, err)
 		}
 	}()
 
-	// Проверка сервера и логирование об успешном запуске если не произошло ошибок
-	select {
-	case err := <-errChan:
-		log.Error("server stopped with error", logger.Error(err))
-		return err
-	case <-time.After(100 * time.Millisecond):
-		log.Info("server started successfully", slog.String("addr", as.server.Addr))
-	}
+	log.Info("server started successfully", slog.String("addr", listener.Addr().String()))
 
 	select {
 	case err := <-errChan:
@@ -88,6 +222,17 @@ func (as *APIServer) Run(ctx context.Context) error {
 	}
 }
 
+// Drain переводит /readyz в отказ и ждёт drainDelay, давая балансировщику
+// время снять инстанс с трафика до закрытия соединений.
+func (as *APIServer) Drain() {
+	as.api.SetShuttingDown()
+
+	if as.drainDelay > 0 {
+		as.logger.Debug("draining traffic before shutdown", slog.Duration("delay", as.drainDelay))
+		time.Sleep(as.drainDelay)
+	}
+}
+
 func (as *APIServer) Stop() error {
 	const op = opServer + "Stop"
 
@@ -101,25 +246,141 @@ func (as *APIServer) Stop() error {
 		return err
 	}
 
+	if err := as.api.CloseSockets(ctx); err != nil {
+		as.logger.Error(fmt.Sprintf("%s: failed to close websockets: %v", op, err))
+		return err
+	}
+
+	if err := as.accessLog.Close(); err != nil {
+		as.logger.Error(fmt.Sprintf("%s: failed to close access log: %v", op, err))
+		return err
+	}
+
 	as.logger.Debug("server shutdown completed")
 	return nil
 }
 
 func (as *APIServer) setupMultiplexer() {
 	mux := http.NewServeMux()
+	as.routes = nil
+
+	as.handle(mux, "GET /", as.api.NotFoundFallback)
+	as.handle(mux, "GET /healthz", as.api.Healthz)
+	as.handle(mux, "GET /readyz", as.api.Readyz)
+	as.handle(mux, "GET /debug/vars", expvar.Handler().ServeHTTP, api.RequireAdmin)
+	// API v1 доступен и без префикса версии, как до введения версионирования
+	for _, prefix := range []string{"", "/v1"} {
+		as.handle(mux, "GET "+prefix+"/quotes", as.api.AllQuotes, as.deprecated)
+		as.handle(mux, "POST "+prefix+"/quotes", as.api.CreateQuote, as.deprecated, as.api.Idempotent)
+		as.handle(mux, "GET "+prefix+"/quotes/random", as.api.RandomQuote, as.deprecated)
+		as.handle(mux, "DELETE "+prefix+"/quotes/{id}", as.api.DeleteQuote, as.deprecated)
+	}
 
-	mux.HandleFunc("GET /", as.api.NotFoundFallback)
-	mux.HandleFunc("GET /quotes", as.api.AllQuotes)
-	mux.HandleFunc("POST /quotes", as.api.CreateQuote)
-	mux.HandleFunc("GET /quotes/random", as.api.RandomQuote)
-	mux.HandleFunc("DELETE /quotes/", as.api.DeleteQuote)
+	as.handle(mux, "GET /v2/quotes", as.api.ListQuotesV2)
+	as.handle(mux, "POST /v2/quotes", as.api.CreateQuoteV2, as.api.Idempotent)
+	as.handle(mux, "GET /v2/quotes/random", as.api.RandomQuoteV2)
+	as.handle(mux, "GET /v2/quotes/{id}", as.api.GetQuoteV2)
+	as.handle(mux, "PUT /v2/quotes/{id}", as.api.UpdateQuoteV2)
+	as.handle(mux, "DELETE /v2/quotes/{id}", as.api.DeleteQuoteV2)
+	// Корзина, история, пакетные операции и отметки появились после API v1
+	// и отвечают в формате v2 по обоим путям
+	for _, prefix := range []string{"", "/v2"} {
+		as.handle(mux, "GET "+prefix+"/trash", as.api.ListTrash)
+		as.handle(mux, "POST "+prefix+"/quotes/{id}/restore", as.api.RestoreQuote)
+		as.handle(mux, "GET "+prefix+"/quotes/{id}/history", as.api.QuoteHistory)
+		as.handle(mux, "POST "+prefix+"/quotes/{id}/revert/{rev}", as.api.RevertQuote)
+		as.handle(mux, "POST "+prefix+"/quotes/batch-ops", as.api.BatchOps, as.api.Idempotent)
+		as.handle(mux, "POST "+prefix+"/quotes/{id}/like", as.api.LikeQuote, api.RequireKey)
+		as.handle(mux, "DELETE "+prefix+"/quotes/{id}/like", as.api.UnlikeQuote, api.RequireKey)
+		as.handle(mux, "GET "+prefix+"/quotes/popular", as.api.PopularQuotes)
+		as.handleStream(mux, "GET "+prefix+"/quotes/stream", as.api.QuoteStream)
+		as.handleStream(mux, "GET "+prefix+"/quotes/ws", as.api.QuoteRotation)
+	}
+	as.handle(mux, "GET /admin/audit", as.api.ListAudit, api.RequireAdmin)
+	as.handle(mux, "GET /admin/webhooks", as.api.ListWebhooks, api.RequireAdmin)
+	as.handle(mux, "POST /admin/webhooks", as.api.CreateWebhook, api.RequireAdmin)
+	as.handle(mux, "GET /admin/webhooks/{id}", as.api.GetWebhook, api.RequireAdmin)
+	as.handle(mux, "DELETE /admin/webhooks/{id}", as.api.DeleteWebhook, api.RequireAdmin)
+	as.handle(mux, "GET /admin/webhooks/{id}/deliveries", as.api.ListWebhookAttempts, api.RequireAdmin)
+	as.handle(mux, "GET /admin/webhooks/dead-letters", as.api.ListDeadDeliveries, api.RequireAdmin)
+	as.handle(mux, "POST /admin/webhooks/dead-letters/{id}/retry", as.api.RetryDelivery, api.RequireAdmin)
+	as.handle(mux, "POST /graphql", as.api.GraphQL)
+	as.handle(mux, "GET /openapi.json", as.api.OpenAPISpec)
+	as.handle(mux, "GET /docs", as.api.Docs)
+
+	// Полноту спецификации проверяет TestOpenAPICoversRoutes, при запуске
+	// расхождение только логируется
+	if missing, err := openapi.Missing(as.routes); err != nil {
+		as.logger.Warn("failed to check openapi spec", logger.Error(err))
+	} else if len(missing) > 0 {
+		as.logger.Warn("routes missing from openapi spec", slog.String("routes", strings.Join(missing, ", ")))
+	}
+	as.handlePreflight(mux)
 
 	middlewares := []func(http.Handler) http.Handler{
+		func(h http.Handler) http.Handler { return api.RequestIDMiddleware(as.logger, h) },
+		func(h http.Handler) http.Handler { return api.TracingMiddleware(as.logger, h) },
+		api.ClientIPMiddleware(as.trustProxy),
+		func(h http.Handler) http.Handler { return api.AccessLogMiddleware(as.accessLog, h) },
+		// Отказ по неверному ключу получает заголовки CORS и попадает в access-лог
+		as.cors.Middleware,
+		api.AuthMiddleware(as.logger, as.keys),
+		func(h http.Handler) http.Handler { return api.ActorMiddleware(as.logger, h) },
 		func(h http.Handler) http.Handler { return api.LoggingMiddleware(as.logger, h) },
-		api.TimeoutMiddleware(5 * time.Second),
+		func(h http.Handler) http.Handler { return api.RecoveryMiddleware(as.logger, h) },
+		as.compress,
 	}
 
 	// Оборачиваем mux в middlewares
 	as.server.Handler = api.ApplyMiddlewares(mux, middlewares...)
 	as.logger.Debug("all handlers registered")
 }
+
+// handle регистрирует маршрут вместе с middleware уровня маршрута.
+// Дополнительные middleware выполняются после общих.
+func (as *APIServer) handle(
+	mux *http.ServeMux,
+	pattern string,
+	handler http.HandlerFunc,
+	extra ...func(http.Handler) http.Handler,
+) {
+	as.routes = append(as.routes, pattern)
+
+	middlewares := []func(http.Handler) http.Handler{
+		api.RouteTracing(pattern),
+		api.TimeoutMiddleware(as.timeouts.For(pattern)),
+	}
+	middlewares = append(middlewares, extra...)
+
+	mux.Handle(pattern, api.ApplyMiddlewares(handler, middlewares...))
+}
+
+// handleStream регистрирует потоковый маршрут (SSE, WebSocket). Таймаут к
+// нему не применяется: TimeoutMiddleware буферизует ответ целиком.
+func (as *APIServer) handleStream(mux *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	as.routes = append(as.routes, pattern)
+	mux.Handle(pattern, api.RouteTracing(pattern)(handler))
+}
+
+// handlePreflight регистрирует OPTIONS для каждого пути из зарегистрированных
+// маршрутов с перечнем его методов. Корневой маршрут перехватывает все
+// неизвестные пути, поэтому для него OPTIONS отвечает только на "/".
+func (as *APIServer) handlePreflight(mux *http.ServeMux) {
+	var paths []string
+	methods := make(map[string][]string)
+	for _, pattern := range as.routes {
+		method, path, _ := strings.Cut(pattern, " ")
+		if _, ok := methods[path]; !ok {
+			paths = append(paths, path)
+		}
+		methods[path] = append(methods[path], method)
+	}
+
+	for _, path := range paths {
+		pattern := http.MethodOptions + " " + path
+		if path == "/" {
+			pattern += "{$}"
+		}
+		mux.Handle(pattern, as.cors.Preflight(methods[path]))
+	}
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -15,12 +18,221 @@ type SQLiteConfig struct {
 type APIConfig struct {
 	Addr string `yaml:"addr" env-default:"127.0.0.1"`
 	Port string `yaml:"port" default:"8090"`
+	// Пауза между переводом /readyz в отказ и остановкой сервера,
+	// за которую балансировщик успевает снять инстанс с трафика.
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"0s"`
+	// Брать IP клиента из X-Forwarded-For/X-Real-IP (только за доверенным прокси)
+	TrustProxyHeaders bool            `yaml:"trust_proxy_headers" env-default:"false"`
+	AccessLog         AccessLogConfig `yaml:"access_log"`
+	Timeouts          TimeoutsConfig  `yaml:"timeouts"`
+	// Максимальный размер тела запроса в байтах
+	MaxBodyBytes int64             `yaml:"max_body_bytes" env-default:"65536"`
+	Versioning   VersioningConfig  `yaml:"versioning"`
+	Compression  CompressionConfig `yaml:"compression"`
+	CORS         CORSConfig        `yaml:"cors"`
+	Stream       StreamConfig      `yaml:"stream"`
+	WebSocket    WebSocketConfig   `yaml:"websocket"`
+	GraphQL      GraphQLConfig     `yaml:"graphql"`
+	Idempotency  IdempotencyConfig `yaml:"idempotency"`
+	Popular      PopularConfig     `yaml:"popular"`
+	Auth         AuthConfig        `yaml:"auth"`
+}
+
+// AuthConfig задаёт ключи API. Без ключей административные маршруты
+// недоступны никому.
+type AuthConfig struct {
+	Keys []APIKeyConfig `yaml:"keys"`
+}
+
+type APIKeyConfig struct {
+	// Имя клиента, под ним записываются его действия
+	Subject string `yaml:"subject"`
+	// SHA-256 ключа в hex: printf %s "$KEY" | sha256sum
+	SHA256 string `yaml:"sha256"`
+	// Доступ к /admin/* и /debug/vars
+	Admin bool `yaml:"admin"`
+}
+
+type PopularConfig struct {
+	// Возраст отметки «нравится», в котором её вес в рейтинге падает вдвое
+	HalfLife time.Duration `yaml:"half_life" env-default:"72h"`
+}
+
+type IdempotencyConfig struct {
+	// Сколько хранится ответ на запрос с Idempotency-Key
+	TTL time.Duration `yaml:"ttl" env-default:"24h"`
+}
+
+// GraphQLConfig ограничивает запросы к /graphql.
+type GraphQLConfig struct {
+	// Максимальная вложенность полей
+	MaxDepth int `yaml:"max_depth" env-default:"10"`
+	// Максимальная оценка стоимости: поле стоит 1, подзапрос списка
+	// умножается на его first
+	MaxComplexity int `yaml:"max_complexity" env-default:"1000"`
+}
+
+// WebSocketConfig задаёт ограничения ротации цитат по WebSocket.
+type WebSocketConfig struct {
+	// Интервал смены цитаты, если клиент его не указал, и допустимые границы
+	DefaultInterval time.Duration `yaml:"default_interval" env-default:"10s"`
+	MinInterval     time.Duration `yaml:"min_interval" env-default:"1s"`
+	MaxInterval     time.Duration `yaml:"max_interval" env-default:"1h"`
+	// Сообщений клиента в секунду и запас для всплесков
+	MessageRate  float64 `yaml:"message_rate" env-default:"2"`
+	MessageBurst int     `yaml:"message_burst" env-default:"5"`
+	// Сколько сообщений сверх лимита подряд терпим до закрытия соединения
+	MaxViolations int `yaml:"max_violations" env-default:"5"`
+	// Интервал ping для обнаружения оборванных соединений
+	PingInterval time.Duration `yaml:"ping_interval" env-default:"30s"`
+}
+
+type StreamConfig struct {
+	// Интервал комментариев-пульсов в потоке событий, не даёт прокси
+	// закрыть простаивающее соединение
+	Heartbeat time.Duration `yaml:"heartbeat" env-default:"15s"`
+}
+
+type CORSConfig struct {
+	// Разрешённые источники: точное значение, шаблон с одной звёздочкой
+	// ("https://*.example.com") или "*". Пустой список отключает CORS.
+	AllowedOrigins   []string      `yaml:"allowed_origins"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env-default:"GET,POST,PUT,DELETE"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env-default:"Content-Type,If-Match,If-None-Match,X-Request-ID,X-Actor,Idempotency-Key,Authorization,traceparent"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env-default:"ETag,Location,X-Request-ID,Deprecation,Sunset,Link,Idempotent-Replayed,WWW-Authenticate"`
+	AllowCredentials bool          `yaml:"allow_credentials" env-default:"false"`
+	MaxAge           time.Duration `yaml:"max_age" env-default:"10m"`
+}
+
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled" env-default:"true"`
+	// Ответы меньше этого размера в байтах не сжимаются
+	MinSize int `yaml:"min_size" env-default:"1024"`
+	// Поддерживаемые кодировки в порядке предпочтения: zstd, br, gzip
+	Encodings []string `yaml:"encodings" env-default:"zstd,br,gzip"`
+}
+
+type VersioningConfig struct {
+	// Даты в формате 2006-01-02 для заголовков Deprecation и Sunset API v1.
+	// Пустое значение отключает заголовок.
+	V1DeprecatedAt string `yaml:"v1_deprecated_at"`
+	V1Sunset       string `yaml:"v1_sunset"`
+}
+
+// V1Dates разбирает даты устаревания и отключения API v1.
+func (vc *VersioningConfig) V1Dates() (deprecatedAt, sunset time.Time, err error) {
+	if vc.V1DeprecatedAt != "" {
+		if deprecatedAt, err = time.Parse(time.DateOnly, vc.V1DeprecatedAt); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid v1_deprecated_at: %w", err)
+		}
+	}
+	if vc.V1Sunset != "" {
+		if sunset, err = time.Parse(time.DateOnly, vc.V1Sunset); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid v1_sunset: %w", err)
+		}
+	}
+	return deprecatedAt, sunset, nil
+}
+
+type TimeoutsConfig struct {
+	Default time.Duration `yaml:"default" env-default:"5s"`
+	// Таймауты отдельных маршрутов по шаблону ServeMux, например "GET /quotes": 10s.
+	// Шаблон без версии действует и на /v1, и на /v2.
+	// Значение 0 отключает таймаут маршрута.
+	Routes map[string]time.Duration `yaml:"routes"`
+}
+
+// For возвращает таймаут для шаблона маршрута. Если шаблон с префиксом
+// версии не задан явно, берётся таймаут того же шаблона без префикса.
+func (tc *TimeoutsConfig) For(pattern string) time.Duration {
+	if d, ok := tc.Routes[pattern]; ok {
+		return d
+	}
+	method, path, _ := strings.Cut(pattern, " ")
+	for _, version := range []string{"/v1/", "/v2/"} {
+		if rest, ok := strings.CutPrefix(path, version); ok {
+			if d, ok := tc.Routes[method+" /"+rest]; ok {
+				return d
+			}
+		}
+	}
+	return tc.Default
+}
+
+type AccessLogConfig struct {
+	// json, combined или off
+	Format string `yaml:"format" env-default:"json"`
+	// Путь к файлу access-лога, пустое значение — stdout
+	Output     string `yaml:"output"`
+	MaxSizeMB  int    `yaml:"max_size_mb" env-default:"100"`
+	MaxBackups int    `yaml:"max_backups" env-default:"5"`
+	// Доля логируемых запросов для путей, например "/healthz": 0.01.
+	// Ответы со статусом 4xx и 5xx логируются всегда.
+	Sampling map[string]float64 `yaml:"sampling"`
+}
+
+type TracingConfig struct {
+	// none, stdout, file (OTLP JSON Lines) или otlp (коллектор по OTLP/HTTP)
+	Exporter    string  `yaml:"exporter" env-default:"none"`
+	File        string  `yaml:"file" env-default:"logs/traces.jsonl"`
+	Endpoint    string  `yaml:"endpoint" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+	ServiceName string  `yaml:"service_name" env-default:"quotes"`
+}
+
+type TrashConfig struct {
+	// Сколько удалённые цитаты хранятся в корзине до очистки командой purge
+	Retention time.Duration `yaml:"retention" env-default:"720h"`
+}
+
+type AuditConfig struct {
+	// Файл для копии журнала аудита в формате JSON Lines, пусто — только SQLite
+	File string `yaml:"file"`
+}
+
+// WebhooksConfig задаёт доставку webhooks.
+type WebhooksConfig struct {
+	// Отключённый диспетчер не доставляет события, но подписки можно настраивать
+	Enabled bool `yaml:"enabled" env-default:"true"`
+	// Как часто проверять очередь повторных попыток
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	// Таймаут одного запроса к получателю
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	// После стольких неудачных попыток доставка попадает в недоставленные
+	MaxAttempts int `yaml:"max_attempts" env-default:"8"`
+	// Задержка перед второй попыткой, дальше удваивается до BackoffMax
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"10s"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"1h"`
+	// Сколько событий и доставок обрабатывается за один проход
+	BatchSize int `yaml:"batch_size" env-default:"50"`
+	// Сколько подписок получают доставки одновременно
+	Concurrency int `yaml:"concurrency" env-default:"8"`
+	// Внутренние сети, куда всё же можно слать webhooks. По умолчанию
+	// loopback, частные и link-local адреса запрещены
+	AllowedNetworks []netip.Prefix `yaml:"allowed_networks"`
+}
+
+// GRPCConfig задаёт gRPC-сервер, который работает рядом с HTTP API.
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled" env-default:"true"`
+	Addr    string `yaml:"addr" env-default:"127.0.0.1"`
+	Port    string `yaml:"port" env-default:"9090"`
+	// Server reflection для grpcurl и подобных клиентов
+	Reflection bool `yaml:"reflection" env-default:"true"`
+	// Сколько ждать завершения активных вызовов при остановке
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"5s"`
 }
 
 type Config struct {
-	SQLite  SQLiteConfig `yaml:"sqlite" required:"true"`
-	API     APIConfig    `yaml:"api" required:"true"`
-	BaseDir string
+	SQLite   SQLiteConfig   `yaml:"sqlite" required:"true"`
+	API      APIConfig      `yaml:"api" required:"true"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Trash    TrashConfig    `yaml:"trash"`
+	Audit    AuditConfig    `yaml:"audit"`
+	Webhooks WebhooksConfig `yaml:"webhooks"`
+	GRPC     GRPCConfig     `yaml:"grpc"`
+	BaseDir  string
 }
 
 func NewConfig() (*Config, error) {
@@ -38,9 +250,55 @@ func NewConfig() (*Config, error) {
 	dbPath := filepath.Join(cfg.BaseDir, cfg.SQLite.Addr)
 	cfg.SQLite.Addr = dbPath
 
+	if cfg.API.AccessLog.Output != "" && !filepath.IsAbs(cfg.API.AccessLog.Output) {
+		cfg.API.AccessLog.Output = filepath.Join(cfg.BaseDir, cfg.API.AccessLog.Output)
+	}
+	if cfg.Audit.File != "" && !filepath.IsAbs(cfg.Audit.File) {
+		cfg.Audit.File = filepath.Join(cfg.BaseDir, cfg.Audit.File)
+	}
+	if !filepath.IsAbs(cfg.Tracing.File) {
+		cfg.Tracing.File = filepath.Join(cfg.BaseDir, cfg.Tracing.File)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return cfg, err
+	}
+
 	return cfg, nil
 }
 
+// validate отклоняет значения, с которыми сервис не может работать.
+func (cfg *Config) validate() error {
+	// Нулевой период полураспада даёт -Inf в формуле рейтинга
+	if cfg.API.Popular.HalfLife <= 0 {
+		return fmt.Errorf("api.popular.half_life must be positive, got %s", cfg.API.Popular.HalfLife)
+	}
+	// Интервалы ниже задают тикеры, time.NewTicker паникует на нуле
+	if cfg.API.Stream.Heartbeat <= 0 {
+		return fmt.Errorf("api.stream.heartbeat must be positive, got %s", cfg.API.Stream.Heartbeat)
+	}
+	if cfg.API.WebSocket.PingInterval <= 0 {
+		return fmt.Errorf("api.websocket.ping_interval must be positive, got %s", cfg.API.WebSocket.PingInterval)
+	}
+
+	if !cfg.Webhooks.Enabled {
+		return nil
+	}
+	if cfg.Webhooks.PollInterval <= 0 {
+		return fmt.Errorf("webhooks.poll_interval must be positive, got %s", cfg.Webhooks.PollInterval)
+	}
+	for name, value := range map[string]int{
+		"webhooks.max_attempts": cfg.Webhooks.MaxAttempts,
+		"webhooks.batch_size":   cfg.Webhooks.BatchSize,
+		"webhooks.concurrency":  cfg.Webhooks.Concurrency,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", name, value)
+		}
+	}
+	return nil
+}
+
 func getBaseDir(cfg *Config) error {
 	// Получаем путь к исполняемому файлу
 	exePath, err := os.Executable()
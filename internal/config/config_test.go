@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+func TestValidateRejectsNonPositiveValues(t *testing.T) {
+	tests := map[string]func(*Config){
+		"api.popular.half_life":       func(c *Config) { c.API.Popular.HalfLife = 0 },
+		"api.stream.heartbeat":        func(c *Config) { c.API.Stream.Heartbeat = 0 },
+		"api.websocket.ping_interval": func(c *Config) { c.API.WebSocket.PingInterval = -time.Second },
+		"webhooks.poll_interval":      func(c *Config) { c.Webhooks.PollInterval = 0 },
+		"webhooks.max_attempts":       func(c *Config) { c.Webhooks.MaxAttempts = 0 },
+		"webhooks.batch_size":         func(c *Config) { c.Webhooks.BatchSize = -1 },
+		"webhooks.concurrency":        func(c *Config) { c.Webhooks.Concurrency = 0 },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			var cfg Config
+			if err := cleanenv.ReadEnv(&cfg); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatalf("defaults rejected: %v", err)
+			}
+
+			mutate(&cfg)
+			if err := cfg.validate(); err == nil {
+				t.Fatalf("%s accepted", name)
+			}
+		})
+	}
+}
+
+func TestValidateSkipsDisabledWebhooks(t *testing.T) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Webhooks.Enabled = false
+	cfg.Webhooks.PollInterval = 0
+
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("disabled webhooks validated: %v", err)
+	}
+}
+
+func TestTimeoutsForVersionedRoutes(t *testing.T) {
+	tc := TimeoutsConfig{
+		Default: 5 * time.Second,
+		Routes: map[string]time.Duration{
+			"GET /quotes":    10 * time.Second,
+			"GET /v2/trash":  time.Second,
+			"GET /quotes/ws": 0,
+		},
+	}
+
+	tests := map[string]time.Duration{
+		"GET /quotes":       10 * time.Second,
+		"GET /v1/quotes":    10 * time.Second,
+		"GET /v2/quotes":    10 * time.Second,
+		"POST /v1/quotes":   5 * time.Second,
+		"GET /v2/trash":     time.Second,
+		"GET /trash":        5 * time.Second,
+		"GET /v1/quotes/ws": 0,
+		"GET /v3/quotes":    5 * time.Second,
+	}
+	for pattern, want := range tests {
+		if got := tc.For(pattern); got != want {
+			t.Errorf("For(%q) = %s, want %s", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия, которые попадают в журнал аудита.
+const (
+	AuditQuoteCreate  = "quote.create"
+	AuditQuoteUpdate  = "quote.update"
+	AuditQuoteDelete  = "quote.delete"
+	AuditQuoteRestore = "quote.restore"
+	AuditQuoteRevert  = "quote.revert"
+	AuditTrashPurge   = "trash.purge"
+	// Отметки «нравится»
+	AuditQuoteLike   = "quote.like"
+	AuditQuoteUnlike = "quote.unlike"
+	// Управление webhooks
+	AuditWebhookCreate = "webhook.create"
+	AuditWebhookDelete = "webhook.delete"
+	AuditWebhookRetry  = "webhook.retry"
+)
+
+// AuditEntry — запись журнала аудита. Before и After содержат JSON объекта
+// до и после изменения; у создания нет Before, у удаления — After.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	Time      time.Time       `json:"time"`
+	Action    string          `json:"action"`
+	EntityID  int64           `json:"entity_id,omitempty"`
+	Actor     string          `json:"actor"`
+	ClientIP  string          `json:"client_ip,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter — параметры выборки журнала аудита. Пустые поля не фильтруют.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	EntityID int64
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// Validate проверяет постраничную выдачу и интервал времени.
+func (f *AuditFilter) Validate() error {
+	page := QuoteFilter{Limit: f.Limit, Offset: f.Offset}
+	errs, _ := page.Validate().(ValidationErrors)
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		errs = append(errs, FieldError{Field: "to", Message: "must not be before from"})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import "fmt"
+
+// MaxBatchOperations — сколько операций можно передать в одном пакете.
+const MaxBatchOperations = 100
+
+// BatchMode — поведение пакета при ошибке одной из операций.
+type BatchMode string
+
+const (
+	// Всё или ничего: первая ошибка откатывает весь пакет
+	BatchAtomic BatchMode = "atomic"
+	// Ошибочная операция откатывается до своей точки сохранения, остальные
+	// применяются
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchOp — тип операции пакета.
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchOperation — одна операция пакета. Version для update и delete
+// работает как If-Match: 0 — изменение безусловное.
+type BatchOperation struct {
+	Op      BatchOp
+	ID      int
+	Quote   Quote
+	Version int64
+}
+
+// Validate проверяет операцию. field — префикс имён полей в ошибках,
+// например "operations[2]".
+func (o *BatchOperation) Validate(field string) error {
+	var errs ValidationErrors
+
+	switch o.Op {
+	case BatchCreate:
+	case BatchUpdate, BatchDelete:
+		if o.ID <= 0 {
+			errs = append(errs, FieldError{Field: field + ".id", Message: "must be a positive integer"})
+		}
+		if o.Version < 0 {
+			errs = append(errs, FieldError{Field: field + ".version", Message: "must not be negative"})
+		}
+	default:
+		return ValidationErrors{{Field: field + ".op", Message: "must be one of create, update, delete"}}
+	}
+
+	if o.Op != BatchDelete {
+		if err := o.Quote.Validate(); err != nil {
+			for _, fe := range err.(ValidationErrors) {
+				errs = append(errs, FieldError{Field: fmt.Sprintf("%s.%s", field, fe.Field), Message: fe.Message})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BatchOutcome — результат операции пакета в хранилище. After — цитата
+// после операции, у удаления её нет.
+type BatchOutcome struct {
+	ID    int
+	After *Quote
+	Err   error
+}
+
+// BatchResult — результат пакета. Committed сообщает, сохранены ли
+// изменения: в режиме atomic ошибка любой операции отменяет все.
+type BatchResult struct {
+	Mode      BatchMode
+	Committed bool
+	Results   []BatchOpResult
+}
+
+// BatchOpResult — результат одной операции. Err равна nil для
+// применённой операции, ErrBatchAborted — для отменённой из-за ошибки
+// другой операции пакета.
+type BatchOpResult struct {
+	Index int
+	Op    BatchOp
+	ID    int
+	Quote *Quote
+	Err   error
+}
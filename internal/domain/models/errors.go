@@ -0,0 +1,20 @@
+package models
+
+import "errors"
+
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	ErrQuoteExists   = errors.New("quote already exists")
+	// Цитата изменилась с момента, когда клиент её прочитал
+	ErrVersionMismatch  = errors.New("quote version mismatch")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	// Доставки нет среди недоставленных
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// Операция пакета отменена, потому что другая операция завершилась ошибкой
+	ErrBatchAborted = errors.New("batch rolled back")
+	// Idempotency-Key уже использован с другим запросом
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+	// Запрос с тем же Idempotency-Key ещё выполняется
+	ErrIdempotencyInProgress = errors.New("idempotent request in progress")
+)
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Типы событий об изменении цитат.
+const (
+	EventQuoteCreated  = "quote.created"
+	EventQuoteUpdated  = "quote.updated"
+	EventQuoteDeleted  = "quote.deleted"
+	EventQuoteRestored = "quote.restored"
+)
+
+// EventTypes — все типы событий, например для подписки webhook.
+var EventTypes = []string{EventQuoteCreated, EventQuoteUpdated, EventQuoteDeleted, EventQuoteRestored}
+
+// QuoteEvent — событие журнала изменений. ID растёт монотонно и
+// используется для продолжения потока после переподключения.
+type QuoteEvent struct {
+	ID      int64        `json:"id"`
+	Type    string       `json:"type"`
+	QuoteID int32        `json:"quote_id"`
+	Quote   QuoteContent `json:"quote"`
+	Actor   string       `json:"actor"`
+	Time    time.Time    `json:"time"`
+}
+
+// EventType возвращает тип события для ревизии. У snapshot события нет.
+func EventType(action RevisionAction) (string, bool) {
+	switch action {
+	case RevisionCreate:
+		return EventQuoteCreated, true
+	case RevisionUpdate, RevisionRevert:
+		return EventQuoteUpdated, true
+	case RevisionDelete:
+		return EventQuoteDeleted, true
+	case RevisionRestore:
+		return EventQuoteRestored, true
+	}
+	return "", false
+}
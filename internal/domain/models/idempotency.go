@@ -0,0 +1,13 @@
+package models
+
+// IdempotencyKeyMaxLength — максимальная длина заголовка Idempotency-Key.
+const IdempotencyKeyMaxLength = 255
+
+// IdempotentResponse — ответ, сохранённый для повторов запроса с тем же
+// Idempotency-Key. Header содержит только заголовки, которые выставил
+// обработчик и которые нужно вернуть при повторе.
+type IdempotentResponse struct {
+	Status int
+	Header map[string]string
+	Body   []byte
+}
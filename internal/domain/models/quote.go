@@ -1,19 +1,159 @@
 package models
 
-import "errors"
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Длина автора совпадает со схемой таблицы quotes (author VARCHAR(100)) и
+// со спецификацией OpenAPI. Текст цитаты (TEXT) по длине не ограничен,
+// его ограничивает только api.max_body_bytes.
+const AuthorMaxLength = 100
+
+// SearchMaxLength — максимальная длина строки поиска по тексту цитат.
+const SearchMaxLength = 1000
 
 type Quote struct {
-	Id     int32
+	Id     int32  `json:"id"`
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+	// Версия строки, увеличивается при каждом изменении. Используется для ETag.
+	Version int64 `json:"-"`
+	// Число отметок «нравится»
+	Likes     int64     `json:"likes"`
+	CreatedAt time.Time `json:"created_at"`
+	// Время последнего изменения текста или автора
+	UpdatedAt time.Time `json:"updated_at"`
+	// Заполнены только у цитат в корзине
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
+}
+
+// Ограничения постраничной выдачи списков.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// QuoteSort — порядок выдачи списка цитат, "-" в начале означает обратный
+// порядок. Пустое значение сортирует по идентификатору.
+type QuoteSort string
+
+const (
+	SortDefault       QuoteSort = ""
+	SortCreatedAt     QuoteSort = "created_at"
+	SortCreatedAtDesc QuoteSort = "-created_at"
+	SortAuthor        QuoteSort = "author"
+	SortAuthorDesc    QuoteSort = "-author"
+	SortLength        QuoteSort = "length"
+	SortLengthDesc    QuoteSort = "-length"
+)
+
+// InvalidSortError описывает недопустимое значение параметра sort.
+var InvalidSortError = FieldError{
+	Field:   "sort",
+	Message: "must be one of created_at, -created_at, author, -author, length, -length",
+}
+
+func (s QuoteSort) Valid() bool {
+	switch s {
+	case SortDefault, SortCreatedAt, SortCreatedAtDesc, SortAuthor, SortAuthorDesc, SortLength, SortLengthDesc:
+		return true
+	}
+	return false
+}
+
+// QuoteFilter — параметры выборки списка цитат.
+type QuoteFilter struct {
 	Author string
-	Quote  string
+	// Подстрока текста или автора, регистр латиницы не учитывается (LIKE в SQLite)
+	Query  string
+	Sort   QuoteSort
+	Limit  int
+	Offset int
 }
 
-func (q *Quote) Validate() error {
-	if q.Author == "" {
-		return errors.New("author field cannot be empty")
+// Validate проверяет параметры постраничной выдачи и сортировку.
+func (f *QuoteFilter) Validate() error {
+	var errs ValidationErrors
+
+	if f.Limit < 1 || f.Limit > MaxPageLimit {
+		errs = append(errs, FieldError{
+			Field:   "limit",
+			Message: fmt.Sprintf("must be between 1 and %d", MaxPageLimit),
+		})
+	}
+	if f.Offset < 0 {
+		errs = append(errs, FieldError{Field: "offset", Message: "must not be negative"})
 	}
-	if q.Quote == "" {
-		return errors.New("quote field cannot be empty")
+	if !f.Sort.Valid() {
+		errs = append(errs, InvalidSortError)
+	}
+
+	if len(errs) > 0 {
+		return errs
 	}
 	return nil
 }
+
+// Normalize убирает пробельные символы по краям полей.
+func (q *Quote) Normalize() {
+	q.Author = strings.TrimSpace(q.Author)
+	q.Quote = strings.TrimSpace(q.Quote)
+}
+
+// Validate проверяет все поля и возвращает ValidationErrors со всеми
+// найденными проблемами или nil.
+func (q *Quote) Validate() error {
+	var errs ValidationErrors
+
+	errs = append(errs, validateText("author", q.Author, AuthorMaxLength, false)...)
+	errs = append(errs, validateText("quote", q.Quote, 0, true)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateText проверяет текстовое поле. Нулевой maxLength не ограничивает
+// длину. В многострочном поле допустимы переводы строк \n и \r\n и табуляция.
+func validateText(field, value string, maxLength int, multiline bool) ValidationErrors {
+	var errs ValidationErrors
+
+	if value == "" {
+		return append(errs, FieldError{Field: field, Message: "must not be empty"})
+	}
+	if !utf8.ValidString(value) {
+		return append(errs, FieldError{Field: field, Message: "must be valid UTF-8"})
+	}
+	if n := utf8.RuneCountInString(value); maxLength > 0 && n > maxLength {
+		errs = append(errs, FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("must be at most %d characters, got %d", maxLength, n),
+		})
+	}
+	for i, r := range value {
+		if unicode.IsControl(r) && !(multiline && allowedInMultiline(value, i, r)) {
+			errs = append(errs, FieldError{Field: field, Message: "must not contain control characters"})
+			break
+		}
+	}
+
+	return errs
+}
+
+// allowedInMultiline сообщает, допустим ли управляющий символ r на позиции i
+// многострочного текста: \r — только перед \n.
+func allowedInMultiline(value string, i int, r rune) bool {
+	switch r {
+	case '\n', '\t':
+		return true
+	case '\r':
+		return i+1 < len(value) && value[i+1] == '\n'
+	}
+	return false
+}
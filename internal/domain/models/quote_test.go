@@ -0,0 +1,31 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuoteValidateText(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote Quote
+		valid bool
+	}{
+		{"plain", Quote{Author: "seneca", Quote: "q"}, true},
+		{"long quote", Quote{Author: "seneca", Quote: strings.Repeat("q", 5000)}, true},
+		{"long author", Quote{Author: strings.Repeat("a", AuthorMaxLength+1), Quote: "q"}, false},
+		{"unix newlines", Quote{Author: "seneca", Quote: "line\nline"}, true},
+		{"windows newlines", Quote{Author: "seneca", Quote: "line\r\nline"}, true},
+		{"tab", Quote{Author: "seneca", Quote: "col\tcol"}, true},
+		{"bare carriage return", Quote{Author: "seneca", Quote: "line\rline"}, false},
+		{"trailing carriage return", Quote{Author: "seneca", Quote: "line\r"}, false},
+		{"other control", Quote{Author: "seneca", Quote: "bell\a"}, false},
+		{"newline in author", Quote{Author: "sen\neca", Quote: "q"}, false},
+		{"tab in author", Quote{Author: "sen\teca", Quote: "q"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.quote.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate() = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// RevisionAction — вид изменения цитаты в истории.
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+	RevisionRevert  RevisionAction = "revert"
+	// Состояние цитаты на момент включения истории
+	RevisionSnapshot RevisionAction = "snapshot"
+)
+
+type QuoteContent struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+}
+
+// QuoteRevision — запись истории цитаты. Номер ревизии совпадает с версией
+// цитаты после изменения.
+type QuoteRevision struct {
+	QuoteID  int32          `json:"quote_id"`
+	Revision int64          `json:"revision"`
+	Action   RevisionAction `json:"action"`
+	// Содержимое после изменения и до него (нет у create и snapshot)
+	Content   QuoteContent  `json:"content"`
+	Previous  *QuoteContent `json:"previous,omitempty"`
+	Actor     string        `json:"actor"`
+	CreatedAt time.Time     `json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "strings"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors — список проблем с полями запроса.
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	parts := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Merge добавляет ошибки other для полей, по которым ошибок ещё нет.
+func (ve ValidationErrors) Merge(other error) ValidationErrors {
+	more, ok := other.(ValidationErrors)
+	if !ok {
+		return ve
+	}
+
+	seen := make(map[string]bool, len(ve))
+	for _, fe := range ve {
+		seen[fe.Field] = true
+	}
+	for _, fe := range more {
+		if !seen[fe.Field] {
+			ve = append(ve, fe)
+		}
+	}
+	return ve
+}
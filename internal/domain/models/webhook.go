@@ -0,0 +1,152 @@
+package models
+
+import (
+	"encoding/json"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookURLMaxLength — максимальная длина адреса получателя.
+	WebhookURLMaxLength = 2048
+	// Секрет короче этого слишком легко подобрать
+	WebhookSecretMinLength = 16
+)
+
+// Webhook — подписка внешнего сервиса на события цитат. Secret отдаётся
+// клиенту только в ответе на создание.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribed сообщает, подписан ли webhook на события типа eventType.
+func (w *Webhook) Subscribed(eventType string) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// Validate проверяет адрес и типы событий подписки. allowed — сети, куда
+// можно слать webhooks, даже если адрес внутренний.
+func (w *Webhook) Validate(allowed []netip.Prefix) error {
+	var errs ValidationErrors
+
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	} else if len(w.URL) > WebhookURLMaxLength {
+		errs = append(errs, FieldError{Field: "url", Message: "must be at most 2048 characters"})
+	} else if !allowedHost(u.Hostname(), allowed) {
+		errs = append(errs, FieldError{Field: "url", Message: "must not point to a loopback, private or link-local address"})
+	}
+
+	// Пустой секрет сгенерирует сервер
+	if w.Secret != "" && len(w.Secret) < WebhookSecretMinLength {
+		errs = append(errs, FieldError{Field: "secret", Message: "must be at least 16 characters"})
+	}
+
+	if len(w.Events) == 0 {
+		errs = append(errs, FieldError{Field: "events", Message: "must not be empty"})
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(EventTypes, event) {
+			errs = append(errs, FieldError{
+				Field:   "events",
+				Message: "must contain only quote.created, quote.updated, quote.deleted, quote.restored",
+			})
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Общий адресный блок провайдеров (RFC 6598), в IsPrivate не входит
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// TargetAllowed сообщает, можно ли слать webhooks на адрес. Без явного
+// разрешения в allowed запрещены loopback, частные, link-local (в том
+// числе метаданные облака 169.254.169.254), multicast и неуказанные адреса.
+func TargetAllowed(addr netip.Addr, allowed []netip.Prefix) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return !addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
+
+// allowedHost отсекает адреса и имена, которые заведомо указывают на саму
+// машину или внутреннюю сеть. Имена, разрешающиеся во внутренние адреса,
+// отсекает диспетчер при подключении.
+func allowedHost(host string, allowed []netip.Prefix) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return TargetAllowed(addr, allowed)
+	}
+	return true
+}
+
+// WebhookDeliveryStatus — состояние доставки события получателю.
+type WebhookDeliveryStatus string
+
+const (
+	// Ждёт первой или повторной попытки
+	DeliveryPending WebhookDeliveryStatus = "pending"
+	// Получатель ответил 2xx
+	DeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// Попытки исчерпаны, доставка в списке недоставленных
+	DeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery — доставка одного события одному получателю из очереди
+// исходящих сообщений.
+type WebhookDelivery struct {
+	ID            int64                 `json:"id"`
+	WebhookID     int64                 `json:"webhook_id"`
+	EventID       int64                 `json:"event_id"`
+	EventType     string                `json:"event_type"`
+	Payload       json.RawMessage       `json:"payload"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt time.Time             `json:"next_attempt_at"`
+	LastError     string                `json:"last_error,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty"`
+}
+
+// WebhookAttempt — запись журнала доставки об одной попытке отправки.
+type WebhookAttempt struct {
+	ID         int64     `json:"id"`
+	DeliveryID int64     `json:"delivery_id"`
+	WebhookID  int64     `json:"webhook_id"`
+	EventID    int64     `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Time       time.Time `json:"time"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestWebhookValidateRejectsInternalTargets(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://example.com/hook", true},
+		{"http://93.184.216.34:8080/hook", true},
+		{"http://localhost/hook", false},
+		{"http://api.localhost./hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://172.16.0.1/hook", false},
+		{"http://100.64.0.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://[fd00::1]/hook", false},
+		{"http://[::ffff:127.0.0.1]/hook", false},
+		{"http://0.0.0.0/hook", false},
+	}
+	for _, tt := range tests {
+		w := Webhook{URL: tt.url, Events: []string{EventQuoteCreated}}
+		if err := w.Validate(nil); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
+
+func TestWebhookValidateAllowsConfiguredNetworks(t *testing.T) {
+	allowed := []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("127.0.0.0/8")}
+
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"http://10.1.2.3/hook", true},
+		{"http://127.0.0.1:8080/hook", true},
+		{"http://localhost/hook", true},
+		{"http://10.2.0.1/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+	}
+	for _, tt := range tests {
+		w := Webhook{URL: tt.url, Events: []string{EventQuoteCreated}}
+		if err := w.Validate(allowed); (err == nil) != tt.valid {
+			t.Errorf("Validate(%q) = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
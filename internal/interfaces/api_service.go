@@ -2,14 +2,43 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/Grino777/quotes/internal/domain/models"
 )
 
 type Service interface {
-	GetQuotes(ctx context.Context) ([]byte, error)
-	CreateQuote(ctx context.Context, quote models.Quote) ([]byte, error)
-	GetRandomQuote(ctx context.Context) ([]byte, error)
-	FilterQuotes(ctx context.Context, author string) ([]byte, error)
-	DeleteQuote(ctx context.Context, id int) ([]byte, error)
+	GetQuotes(ctx context.Context, sort models.QuoteSort) ([]models.Quote, error)
+	GetQuote(ctx context.Context, id int) (models.Quote, error)
+	ListQuotes(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error)
+	CountQuotesByAuthor(ctx context.Context, authors []string) (map[string]int, error)
+	CreateQuote(ctx context.Context, quote models.Quote) (models.Quote, error)
+	GetRandomQuote(ctx context.Context, author string) (models.Quote, error)
+	FilterQuotes(ctx context.Context, author string, sort models.QuoteSort) ([]models.Quote, error)
+	UpdateQuote(ctx context.Context, id int, quote models.Quote, version int64) (models.Quote, error)
+	DeleteQuote(ctx context.Context, id int, version int64) error
+	ApplyBatch(ctx context.Context, mode models.BatchMode, ops []models.BatchOperation) (models.BatchResult, error)
+	QuoteHistory(ctx context.Context, id int, limit, offset int) ([]models.QuoteRevision, int, error)
+	RevertQuote(ctx context.Context, id int, rev int64, version int64) (models.Quote, error)
+	ListTrash(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error)
+	RestoreQuote(ctx context.Context, id int) (models.Quote, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	QuoteEvents(ctx context.Context, afterID int64, limit int) ([]models.QuoteEvent, error)
+	LastEventID(ctx context.Context) (int64, error)
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, id int64) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	ListWebhookAttempts(ctx context.Context, webhookID int64, limit, offset int) ([]models.WebhookAttempt, int, error)
+	ListDeadDeliveries(ctx context.Context, limit, offset int) ([]models.WebhookDelivery, int, error)
+	RetryDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error)
+	LikeQuote(ctx context.Context, id int, subject string) (models.Quote, error)
+	UnlikeQuote(ctx context.Context, id int, subject string) (models.Quote, error)
+	PopularQuotes(ctx context.Context, halfLife time.Duration, filter models.QuoteFilter) ([]models.Quote, int, error)
+	BeginIdempotent(ctx context.Context, key, requestHash string, ttl, lease time.Duration) (*models.IdempotentResponse, error)
+	SaveIdempotent(ctx context.Context, key string, resp models.IdempotentResponse) error
+	ReleaseIdempotent(ctx context.Context, key string) error
+	CollectionVersion(ctx context.Context) (int64, error)
+	Ping(ctx context.Context) error
 }
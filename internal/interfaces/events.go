@@ -0,0 +1,11 @@
+package interfaces
+
+import "github.com/Grino777/quotes/internal/domain/models"
+
+type EventPublisher interface {
+	Publish(event models.QuoteEvent)
+}
+
+type EventSubscriber interface {
+	Subscribe() (<-chan models.QuoteEvent, func())
+}
@@ -0,0 +1,43 @@
+package actor
+
+import "context"
+
+const (
+	// Header — заголовок, в котором шлюз или клиент передаёт автора изменения.
+	Header = "X-Actor"
+
+	Anonymous = "anonymous"
+	CLI       = "cli"
+
+	maxLength = 100
+)
+
+type ctxKey struct{}
+
+// Valid проверяет, что имя безопасно писать в базу, логи и заголовки.
+func Valid(name string) bool {
+	if name == "" || len(name) > maxLength {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '@':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func WithActor(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, name)
+}
+
+// FromContext возвращает автора текущей операции или Anonymous.
+func FromContext(ctx context.Context) string {
+	if name, _ := ctx.Value(ctxKey{}).(string); name != "" {
+		return name
+	}
+	return Anonymous
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/lib/actor"
+)
+
+// Principal — клиент, предъявивший действительный ключ API.
+type Principal struct {
+	Subject string
+	Admin   bool
+}
+
+type ctxKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext возвращает клиента текущего запроса, если он аутентифицирован.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+	return p, ok
+}
+
+// Header — заголовок HTTP и ключ метаданных gRPC с ключом API.
+const Header = "Authorization"
+
+// BearerToken извлекает ключ из значения "Bearer <ключ>".
+func BearerToken(value string) (string, bool) {
+	scheme, token, _ := strings.Cut(value, " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+type key struct {
+	sum       [sha256.Size]byte
+	principal Principal
+}
+
+// Keys сверяет ключи API с их хешами SHA-256 из конфигурации. Сами ключи
+// в конфигурации не хранятся.
+type Keys struct {
+	keys []key
+}
+
+func NewKeys(cfg []config.APIKeyConfig) (*Keys, error) {
+	keys := make([]key, 0, len(cfg))
+	for i, kc := range cfg {
+		if !actor.Valid(kc.Subject) {
+			return nil, fmt.Errorf("auth key %d: invalid subject %q", i, kc.Subject)
+		}
+		sum, err := hex.DecodeString(kc.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("auth key %d: sha256 must be %d hex characters", i, 2*sha256.Size)
+		}
+		k := key{principal: Principal{Subject: kc.Subject, Admin: kc.Admin}}
+		copy(k.sum[:], sum)
+		keys = append(keys, k)
+	}
+	return &Keys{keys: keys}, nil
+}
+
+// Lookup возвращает владельца ключа. Хеш сравнивается со всеми записями
+// за постоянное время, чтобы время ответа не выдавало совпадение.
+func (k *Keys) Lookup(token string) (Principal, bool) {
+	sum := sha256.Sum256([]byte(token))
+
+	var (
+		found Principal
+		ok    bool
+	)
+	for _, candidate := range k.keys {
+		if subtle.ConstantTimeCompare(sum[:], candidate.sum[:]) == 1 {
+			found, ok = candidate.principal, true
+		}
+	}
+	return found, ok
+}
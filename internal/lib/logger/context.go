@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type ctxKey struct{}
+
+// WithContext сохраняет логгер запроса в контексте.
+func WithContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, log)
+}
+
+// FromContext возвращает логгер запроса или fallback, если его нет в контексте.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if log, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok && log != nil {
+		return log
+	}
+	return fallback
+}
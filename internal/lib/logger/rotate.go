@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RotatingFile — файл лога, который при превышении maxSize переименовывается
+// в path.1 (старые копии сдвигаются до path.<maxBackups>) и открывается заново.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	closed     bool
+}
+
+func NewRotatingFile(path string, maxSizeMB, maxBackups int) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, os.ErrClosed
+	}
+	// Файл не открылся после неудачной ротации: пробуем снова
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	var rotateErr error
+	if rf.maxSize > 0 && rf.size+int64(len(p)) > rf.maxSize && rf.size > 0 {
+		rotateErr = rf.rotate()
+		if rf.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	// Если ротация не удалась, запись продолжается в прежний файл сверх
+	// лимита: потерять строки лога хуже
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	rf.closed = true
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	rf.file = f
+	rf.size = info.Size()
+	return nil
+}
+
+// rotate переименовывает файл и открывает новый. Если переименовать не
+// удалось, снова открывается прежний файл по тому же пути, а ошибка
+// возвращается вызывающему.
+func (rf *RotatingFile) rotate() error {
+	closeErr := rf.file.Close()
+	rf.file = nil
+	if closeErr != nil {
+		closeErr = fmt.Errorf("failed to close log file: %w", closeErr)
+		return errors.Join(closeErr, rf.open())
+	}
+
+	if err := rf.shift(); err != nil {
+		return errors.Join(err, rf.open())
+	}
+
+	return rf.open()
+}
+
+// shift сдвигает копии и освобождает путь для нового файла.
+func (rf *RotatingFile) shift() error {
+	if rf.maxBackups > 0 {
+		for i := rf.maxBackups - 1; i >= 1; i-- {
+			src := fmt.Sprintf("%s.%d", rf.path, i)
+			if _, err := os.Stat(src); err == nil {
+				_ = os.Rename(src, fmt.Sprintf("%s.%d", rf.path, i+1))
+			}
+		}
+		if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(rf.path); err != nil {
+		return fmt.Errorf("failed to truncate log file: %w", err)
+	}
+	return nil
+}
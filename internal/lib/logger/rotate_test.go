@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	rf, err := NewRotatingFile(path, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+	rf.maxSize = 10
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, want := range map[string]string{path: "third\n", path + ".1": "second\n", path + ".2": "first\n"} {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+}
+
+// Неудачное переименование не закрывает лог: ошибка возвращается, а строки
+// продолжают писаться в прежний файл.
+func TestRotatingFileKeepsWritingWhenRenameFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	rf, err := NewRotatingFile(path, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+	rf.maxSize = 10
+
+	// Каталог на месте копии: переименовать файл в него нельзя
+	if err := os.MkdirAll(filepath.Join(path+".1", "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rf.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	n, err := rf.Write([]byte("second\n"))
+	if err == nil || !strings.Contains(err.Error(), "failed to rotate log file") {
+		t.Fatalf("err = %v, want rotation error", err)
+	}
+	if n != len("second\n") {
+		t.Fatalf("wrote %d bytes, want the line kept", n)
+	}
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("third\n")); err != nil {
+		t.Fatalf("write after the obstacle is gone: %v", err)
+	}
+
+	rotated, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rotated) != "first\nsecond\n" {
+		t.Errorf("rotated file = %q, want both lines written before rotation", rotated)
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	rf, err := NewRotatingFile(filepath.Join(t.TempDir(), "access.log"), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rf.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Fatalf("err = %v, want os.ErrClosed", err)
+	}
+}
@@ -0,0 +1,9 @@
+package metrics
+
+import "expvar"
+
+// Счётчики публикуются через expvar и доступны на /debug/vars.
+var (
+	HTTPPanics = expvar.NewInt("http_panics_total")
+	GRPCPanics = expvar.NewInt("grpc_panics_total")
+)
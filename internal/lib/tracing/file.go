@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// fileClient пишет спаны в файл в формате OTLP JSON Lines: по одному
+// ExportTraceServiceRequest на строку. Такой файл читает otlpjsonfile
+// receiver коллектора.
+type fileClient struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileClient(path string) *fileClient {
+	return &fileClient{path: path}
+}
+
+func (fc *fileClient) Start(ctx context.Context) error {
+	f, err := os.OpenFile(fc.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+
+	fc.mu.Lock()
+	fc.file = f
+	fc.mu.Unlock()
+	return nil
+}
+
+func (fc *fileClient) Stop(ctx context.Context) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.file == nil {
+		return nil
+	}
+	err := fc.file.Close()
+	fc.file = nil
+	return err
+}
+
+func (fc *fileClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	data, err := marshalOTLPJSON(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.file == nil {
+		return os.ErrClosed
+	}
+	_, err = fc.file.Write(append(data, '\n'))
+	return err
+}
+
+// idFields — поля, которые OTLP JSON кодирует hex-строкой, а protojson
+// по умолчанию base64.
+var idFields = map[string]bool{"traceId": true, "spanId": true, "parentSpanId": true}
+
+func marshalOTLPJSON(req *coltracepb.ExportTraceServiceRequest) ([]byte, error) {
+	raw, err := protojson.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	hexIDs(doc)
+	return json.Marshal(doc)
+}
+
+func hexIDs(v any) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if s, ok := child.(string); ok && idFields[k] {
+				if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+					node[k] = hex.EncodeToString(b)
+				}
+				continue
+			}
+			hexIDs(child)
+		}
+	case []any:
+		for _, child := range node {
+			hexIDs(child)
+		}
+	}
+}
@@ -2,15 +2,14 @@ package api
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"log/slog"
 	"strings"
 
 	"github.com/Grino777/quotes/internal/domain/models"
 	"github.com/Grino777/quotes/internal/interfaces"
 	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
 	"github.com/Grino777/quotes/internal/storage/sqlite"
 )
 
@@ -19,134 +18,253 @@ const apiOp = "services.api."
 type Service struct {
 	logger  *slog.Logger
 	storage interfaces.Storage
+	// Шина событий об изменениях, nil — события не публикуются (команды CLI)
+	events interfaces.EventPublisher
 }
 
-func NewService(log *slog.Logger, storage interfaces.Storage) *Service {
-	return &Service{logger: log, storage: storage}
+func NewService(
+	log *slog.Logger,
+	storage interfaces.Storage,
+	events interfaces.EventPublisher,
+) *Service {
+	return &Service{logger: log, storage: storage, events: events}
 }
 
-func (s *Service) GetQuotes(ctx context.Context) ([]byte, error) {
+func (s *Service) GetQuotes(ctx context.Context, sort models.QuoteSort) ([]models.Quote, error) {
 	const op = apiOp + "GetQuotes"
 
-	log := s.logger.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	quotes, err := s.storage.GetQuotes(ctx)
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	quotes, err := s.storage.GetQuotes(ctx, sort)
 	if err != nil {
+		tracing.Error(span, err)
 		log.Error("failed to get quotes", logger.Error(err))
 		return nil, err
 	}
 
-	if len(quotes) == 0 {
-		return []byte("{}"), nil
+	return quotes, nil
+}
+
+// ListQuotes возвращает страницу цитат и общее число цитат под фильтром.
+func (s *Service) ListQuotes(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error) {
+	const op = apiOp + "ListQuotes"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	filter.Author = strings.ToLower(filter.Author)
+
+	quotes, total, err := s.storage.ListQuotes(ctx, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list quotes", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return quotes, total, nil
+}
+
+// CountQuotesByAuthor возвращает число цитат каждого из авторов. Имена
+// приводятся к нижнему регистру, как при фильтре по автору, и в таком
+// виде служат ключами результата.
+func (s *Service) CountQuotesByAuthor(ctx context.Context, authors []string) (map[string]int, error) {
+	const op = apiOp + "CountQuotesByAuthor"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	lower := make([]string, len(authors))
+	for i, a := range authors {
+		lower[i] = strings.ToLower(a)
 	}
 
-	data, err := json.Marshal(quotes)
+	counts, err := s.storage.CountQuotesByAuthor(ctx, lower)
 	if err != nil {
-		log.Error("failed to marshaling data", logger.Error(err))
+		tracing.Error(span, err)
+		log.Error("failed to count quotes by author", logger.Error(err))
 		return nil, err
 	}
 
-	return data, nil
+	return counts, nil
+}
+
+func (s *Service) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	const op = apiOp + "GetQuote"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	quote, err := s.storage.GetQuote(ctx, id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrQuoteNotExists) {
+			return models.Quote{}, models.ErrQuoteNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to get quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	return quote, nil
 }
 
-func (s *Service) CreateQuote(ctx context.Context, quote models.Quote) ([]byte, error) {
+// CreateQuote сохраняет цитату и возвращает её в том виде, в котором она
+// записана в базу (с идентификатором и нормализованным автором).
+func (s *Service) CreateQuote(ctx context.Context, quote models.Quote) (models.Quote, error) {
 	const op = apiOp + "CreateQuote"
 
-	log := s.logger.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	res, err := s.storage.CreateQuote(ctx, quote)
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	id, err := s.storage.CreateQuote(ctx, quote)
 	if err != nil {
 		if errors.Is(err, sqlite.ErrAlreadyExist) {
-			data, err := json.Marshal(map[string]string{"error": "quote already exist"})
-			if err != nil {
-				log.Error("failed to marshaling data", logger.Error(err))
-				return nil, err
-			}
-			return data, nil
+			return models.Quote{}, models.ErrQuoteExists
 		}
+		tracing.Error(span, err)
 		log.Error("failed to save quote in database", logger.Error(err))
-		return nil, err
+		return models.Quote{}, err
 	}
 
-	data, err := json.Marshal(map[string]any{"result": "success", "id": res})
+	created, err := s.storage.GetQuote(ctx, int(id))
 	if err != nil {
-		log.Error("failed to marshaling data", logger.Error(err))
-		return nil, err
+		tracing.Error(span, err)
+		log.Error("failed to get created quote", logger.Error(err))
+		return models.Quote{}, err
 	}
 
-	return data, nil
+	s.publish(ctx, int(id))
+	return created, nil
 }
 
-func (s *Service) GetRandomQuote(ctx context.Context) ([]byte, error) {
+func (s *Service) GetRandomQuote(ctx context.Context, author string) (models.Quote, error) {
 	const op = apiOp + "GetRandomQuote"
 
-	log := s.logger.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	res, err := s.storage.GetRandomQuote(ctx)
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	res, err := s.storage.GetRandomQuote(ctx, strings.ToLower(author))
 	if err != nil {
 		if errors.Is(err, sqlite.ErrQuoteNotExists) {
-			return []byte("{}"), nil
+			return models.Quote{}, models.ErrQuoteNotFound
 		}
+		tracing.Error(span, err)
 		log.Error("failed to get random quote", logger.Error(err))
-		return nil, err
+		return models.Quote{}, err
 	}
 
-	data, err := json.Marshal(res)
-	if err != nil {
-		log.Error("failed to marshaling data", logger.Error(err))
-		return nil, err
-	}
-	return data, nil
+	return res, nil
 }
 
-func (s *Service) FilterQuotes(ctx context.Context, author string) ([]byte, error) {
-	const op = apiOp + "CreateQuote"
+func (s *Service) FilterQuotes(ctx context.Context, author string, sort models.QuoteSort) ([]models.Quote, error) {
+	const op = apiOp + "FilterQuotes"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	log := s.logger.With(slog.String("op", op))
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
 
-	res, err := s.storage.FilterQuotes(ctx, strings.ToLower(author))
+	res, err := s.storage.FilterQuotes(ctx, strings.ToLower(author), sort)
 	if err != nil {
+		tracing.Error(span, err)
 		log.Error("failed to get filtered record", logger.Error(err))
 		return nil, err
 	}
 
-	if len(res) == 0 {
-		return []byte("{}"), nil
+	return res, nil
+}
+
+// UpdateQuote заменяет цитату. Ненулевой version включает оптимистичную
+// блокировку: если цитата уже изменилась, возвращается ErrVersionMismatch.
+func (s *Service) UpdateQuote(ctx context.Context, id int, quote models.Quote, version int64) (models.Quote, error) {
+	const op = apiOp + "UpdateQuote"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := s.storage.UpdateQuote(ctx, id, quote, version); err != nil {
+		switch {
+		case errors.Is(err, sqlite.ErrQuoteNotExists):
+			return models.Quote{}, models.ErrQuoteNotFound
+		case errors.Is(err, sqlite.ErrVersionMismatch):
+			return models.Quote{}, models.ErrVersionMismatch
+		case errors.Is(err, sqlite.ErrAlreadyExist):
+			return models.Quote{}, models.ErrQuoteExists
+		}
+		tracing.Error(span, err)
+		log.Error("failed to update quote", logger.Error(err))
+		return models.Quote{}, err
 	}
 
-	data, err := json.Marshal(res)
+	updated, err := s.storage.GetQuote(ctx, id)
 	if err != nil {
-		log.Error("failed to marshaling data", logger.Error(err))
-		return nil, err
+		tracing.Error(span, err)
+		log.Error("failed to get updated quote", logger.Error(err))
+		return models.Quote{}, err
 	}
 
-	return data, nil
+	s.publish(ctx, id)
+	return updated, nil
 }
 
-func (s *Service) DeleteQuote(ctx context.Context, id int) ([]byte, error) {
-	const op = apiOp + "CreateQuote"
+// DeleteQuote переносит цитату в корзину. Ненулевой version работает как
+// в UpdateQuote.
+func (s *Service) DeleteQuote(ctx context.Context, id int, version int64) error {
+	const op = apiOp + "DeleteQuote"
 
-	log := s.logger.With(slog.String("op", op))
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
 
-	if err := s.storage.DeleteQuote(ctx, id); err != nil {
-		if errors.Is(err, sqlite.ErrQuoteNotExists) {
-			r := fmt.Sprintf("no quote found with id %d", id)
-			data, err := json.Marshal(map[string]string{"error": r})
-			if err != nil {
-				log.Error("failed to marshaling data", logger.Error(err))
-				return nil, err
-			}
-			return data, nil
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := s.storage.DeleteQuote(ctx, id, version); err != nil {
+		switch {
+		case errors.Is(err, sqlite.ErrQuoteNotExists):
+			return models.ErrQuoteNotFound
+		case errors.Is(err, sqlite.ErrVersionMismatch):
+			return models.ErrVersionMismatch
 		}
-		log.Error("failed to get filtered record", logger.Error(err))
-		return nil, err
+		tracing.Error(span, err)
+		log.Error("failed to delete quote", logger.Error(err))
+		return err
 	}
 
-	data, err := json.Marshal(
-		map[string]string{"result": fmt.Sprintf("quote with id: %d successfully deleted", id)})
+	s.publish(ctx, id)
+	return nil
+}
+
+// CollectionVersion возвращает счётчик изменений коллекции цитат.
+func (s *Service) CollectionVersion(ctx context.Context) (int64, error) {
+	const op = apiOp + "CollectionVersion"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	version, err := s.storage.CollectionVersion(ctx)
 	if err != nil {
-		log.Error("failed to marshaling data", logger.Error(err))
-		return nil, err
+		tracing.Error(span, err)
+		logger.FromContext(ctx, s.logger).Error("failed to get collection version",
+			slog.String("op", op), logger.Error(err))
+		return 0, err
 	}
-	return data, nil
+
+	return version, nil
+}
+
+func (s *Service) Ping(ctx context.Context) error {
+	return s.storage.Ping(ctx)
 }
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// ListAudit возвращает страницу журнала аудита, новые записи первыми.
+func (s *Service) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	const op = apiOp + "ListAudit"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	entries, total, err := s.storage.ListAudit(ctx, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list audit entries", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// ApplyBatch выполняет операции пакета в одной транзакции. Невалидные
+// операции в базу не передаются: в режиме atomic такая операция отменяет
+// весь пакет, в best_effort пропускается только она. Ошибка возвращается,
+// только если пакет прерван неожиданной ошибкой хранилища.
+func (s *Service) ApplyBatch(
+	ctx context.Context,
+	mode models.BatchMode,
+	ops []models.BatchOperation,
+) (models.BatchResult, error) {
+	const op = apiOp + "ApplyBatch"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	result := models.BatchResult{Mode: mode, Results: make([]models.BatchOpResult, len(ops))}
+
+	valid := make([]models.BatchOperation, 0, len(ops))
+	indexes := make([]int, 0, len(ops))
+	for i, o := range ops {
+		result.Results[i] = models.BatchOpResult{Index: i, Op: o.Op, ID: o.ID}
+		if err := o.Validate(fmt.Sprintf("operations[%d]", i)); err != nil {
+			result.Results[i].Err = err
+			continue
+		}
+		valid = append(valid, o)
+		indexes = append(indexes, i)
+	}
+
+	if mode == models.BatchAtomic && len(valid) < len(ops) {
+		abortPending(result.Results)
+		return result, nil
+	}
+
+	outcomes, committed, err := s.storage.ApplyBatch(ctx, valid, mode)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to apply batch", logger.Error(err))
+		return models.BatchResult{}, err
+	}
+	result.Committed = committed
+
+	for i, outcome := range outcomes {
+		res := &result.Results[indexes[i]]
+		res.ID = outcome.ID
+		res.Err = batchError(outcome.Err)
+		if res.Err == nil {
+			res.Quote = outcome.After
+		}
+	}
+
+	if !committed {
+		abortPending(result.Results)
+		return result, nil
+	}
+
+	// Журнал аудита записан в транзакции пакета, остаётся оповестить подписчиков
+	for _, outcome := range outcomes {
+		if outcome.Err == nil {
+			s.publish(ctx, outcome.ID)
+		}
+	}
+
+	return result, nil
+}
+
+// abortPending помечает отменёнными операции без собственной ошибки и
+// убирает их результаты: после отката транзакции их не существует.
+func abortPending(results []models.BatchOpResult) {
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		results[i].Err = models.ErrBatchAborted
+		results[i].Quote = nil
+		if results[i].Op == models.BatchCreate {
+			results[i].ID = 0
+		}
+	}
+}
+
+func batchError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sqlite.ErrQuoteNotExists):
+		return models.ErrQuoteNotFound
+	case errors.Is(err, sqlite.ErrAlreadyExist):
+		return models.ErrQuoteExists
+	case errors.Is(err, sqlite.ErrVersionMismatch):
+		return models.ErrVersionMismatch
+	}
+	return err
+}
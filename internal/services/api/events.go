@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// QuoteEvents возвращает события журнала изменений после afterID.
+func (s *Service) QuoteEvents(ctx context.Context, afterID int64, limit int) ([]models.QuoteEvent, error) {
+	const op = apiOp + "QuoteEvents"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	events, err := s.storage.QuoteEvents(ctx, afterID, limit)
+	if err != nil {
+		tracing.Error(span, err)
+		logger.FromContext(ctx, s.logger).Error("failed to get quote events",
+			slog.String("op", op), logger.Error(err))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// LastEventID возвращает идентификатор последнего события журнала изменений.
+func (s *Service) LastEventID(ctx context.Context) (int64, error) {
+	const op = apiOp + "LastEventID"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	id, err := s.storage.LastEventID(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		logger.FromContext(ctx, s.logger).Error("failed to get last event id",
+			slog.String("op", op), logger.Error(err))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// publish отправляет в шину событие, только что записанное в журнал
+// изменений. Подписчики дочитывают пропуски из журнала, поэтому ошибка
+// только логируется.
+func (s *Service) publish(ctx context.Context, quoteID int) {
+	if s.events == nil {
+		return
+	}
+
+	event, err := s.storage.LastQuoteEvent(ctx, quoteID)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error("failed to publish quote event",
+			slog.Int("quote_id", quoteID), logger.Error(err))
+		return
+	}
+	s.events.Publish(event)
+}
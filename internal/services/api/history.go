@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// QuoteHistory возвращает страницу ревизий цитаты, новые первыми.
+func (s *Service) QuoteHistory(ctx context.Context, id int, limit, offset int) ([]models.QuoteRevision, int, error) {
+	const op = apiOp + "QuoteHistory"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	revisions, total, err := s.storage.QuoteHistory(ctx, id, limit, offset)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrQuoteNotExists) {
+			return nil, 0, models.ErrQuoteNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to get quote history", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// RevertQuote возвращает цитате содержимое ревизии rev. Ненулевой version
+// работает как в UpdateQuote.
+func (s *Service) RevertQuote(ctx context.Context, id int, rev int64, version int64) (models.Quote, error) {
+	const op = apiOp + "RevertQuote"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := s.storage.RevertQuote(ctx, id, rev, version); err != nil {
+		switch {
+		case errors.Is(err, sqlite.ErrQuoteNotExists):
+			return models.Quote{}, models.ErrQuoteNotFound
+		case errors.Is(err, sqlite.ErrRevisionNotExists):
+			return models.Quote{}, models.ErrRevisionNotFound
+		case errors.Is(err, sqlite.ErrVersionMismatch):
+			return models.Quote{}, models.ErrVersionMismatch
+		case errors.Is(err, sqlite.ErrAlreadyExist):
+			return models.Quote{}, models.ErrQuoteExists
+		}
+		tracing.Error(span, err)
+		log.Error("failed to revert quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	reverted, err := s.storage.GetQuote(ctx, id)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to get reverted quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	s.publish(ctx, id)
+	return reverted, nil
+}
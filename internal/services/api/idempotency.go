@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// BeginIdempotent занимает Idempotency-Key текущего автора на ttl, пока
+// ответа нет — с арендой на lease. nil означает, что запрос нужно
+// выполнить, иначе возвращается сохранённый ответ на такой же запрос.
+func (s *Service) BeginIdempotent(
+	ctx context.Context,
+	key, requestHash string,
+	ttl, lease time.Duration,
+) (*models.IdempotentResponse, error) {
+	const op = apiOp + "BeginIdempotent"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	stored, err := s.storage.BeginIdempotent(ctx, key, requestHash, ttl, lease)
+	if err != nil {
+		switch {
+		case errors.Is(err, sqlite.ErrIdempotencyMismatch):
+			return nil, models.ErrIdempotencyKeyReused
+		case errors.Is(err, sqlite.ErrIdempotencyInProgress):
+			return nil, models.ErrIdempotencyInProgress
+		}
+		tracing.Error(span, err)
+		log.Error("failed to begin idempotent request", logger.Error(err))
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// SaveIdempotent сохраняет ответ для повторов запроса.
+func (s *Service) SaveIdempotent(ctx context.Context, key string, resp models.IdempotentResponse) error {
+	return s.storage.SaveIdempotent(ctx, key, resp)
+}
+
+// ReleaseIdempotent освобождает ключ запроса, ответ на который не сохраняется.
+func (s *Service) ReleaseIdempotent(ctx context.Context, key string) error {
+	return s.storage.ReleaseIdempotent(ctx, key)
+}
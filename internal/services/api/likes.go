@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// LikeQuote ставит цитате отметку клиента subject и возвращает цитату с
+// обновлённым числом отметок.
+func (s *Service) LikeQuote(ctx context.Context, id int, subject string) (models.Quote, error) {
+	return s.changeLike(ctx, apiOp+"LikeQuote", id, subject, s.storage.LikeQuote)
+}
+
+// UnlikeQuote снимает отметку клиента subject.
+func (s *Service) UnlikeQuote(ctx context.Context, id int, subject string) (models.Quote, error) {
+	return s.changeLike(ctx, apiOp+"UnlikeQuote", id, subject, s.storage.UnlikeQuote)
+}
+
+func (s *Service) changeLike(
+	ctx context.Context,
+	op string,
+	id int,
+	subject string,
+	change func(ctx context.Context, id int, subject string) error,
+) (models.Quote, error) {
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := change(ctx, id, subject); err != nil {
+		if errors.Is(err, sqlite.ErrQuoteNotExists) {
+			return models.Quote{}, models.ErrQuoteNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to change like", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	quote, err := s.storage.GetQuote(ctx, id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrQuoteNotExists) {
+			return models.Quote{}, models.ErrQuoteNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to get liked quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+// PopularQuotes возвращает страницу отмеченных цитат, самые популярные
+// первыми. Вес отметки убывает с возрастом экспоненциально, halfLife —
+// возраст, в котором он уменьшается вдвое.
+func (s *Service) PopularQuotes(
+	ctx context.Context,
+	halfLife time.Duration,
+	filter models.QuoteFilter,
+) ([]models.Quote, int, error) {
+	const op = apiOp + "PopularQuotes"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	filter.Author = strings.ToLower(filter.Author)
+
+	quotes, total, err := s.storage.PopularQuotes(ctx, halfLife, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to get popular quotes", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return quotes, total, nil
+}
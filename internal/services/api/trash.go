@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// ListTrash возвращает страницу удалённых цитат.
+func (s *Service) ListTrash(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error) {
+	const op = apiOp + "ListTrash"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	filter.Author = strings.ToLower(filter.Author)
+
+	quotes, total, err := s.storage.ListTrash(ctx, filter)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list trash", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return quotes, total, nil
+}
+
+// RestoreQuote возвращает цитату из корзины.
+func (s *Service) RestoreQuote(ctx context.Context, id int) (models.Quote, error) {
+	const op = apiOp + "RestoreQuote"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := s.storage.RestoreQuote(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, sqlite.ErrQuoteNotExists):
+			return models.Quote{}, models.ErrQuoteNotFound
+		case errors.Is(err, sqlite.ErrAlreadyExist):
+			return models.Quote{}, models.ErrQuoteExists
+		}
+		tracing.Error(span, err)
+		log.Error("failed to restore quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	restored, err := s.storage.GetQuote(ctx, id)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to get restored quote", logger.Error(err))
+		return models.Quote{}, err
+	}
+
+	s.publish(ctx, id)
+	return restored, nil
+}
+
+// PurgeTrash окончательно удаляет цитаты, пролежавшие в корзине дольше retention.
+func (s *Service) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	const op = apiOp + "PurgeTrash"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	purged, err := s.storage.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to purge trash", logger.Error(err))
+		return 0, err
+	}
+
+	log.Info("trash purged", slog.Int64("purged", purged), slog.Duration("retention", retention))
+	return purged, nil
+}
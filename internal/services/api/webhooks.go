@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+// Длина генерируемого секрета подписи в байтах
+const webhookSecretBytes = 32
+
+// CreateWebhook добавляет подписку. Если секрет не задан, он генерируется;
+// в ответе секрет возвращается единственный раз.
+func (s *Service) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	const op = apiOp + "CreateWebhook"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if webhook.Secret == "" {
+		secret := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			err = fmt.Errorf("%s: failed to generate secret: %w", op, err)
+			tracing.Error(span, err)
+			log.Error("failed to create webhook", logger.Error(err))
+			return models.Webhook{}, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	id, err := s.storage.CreateWebhook(ctx, webhook)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to create webhook", logger.Error(err))
+		return models.Webhook{}, err
+	}
+
+	created, err := s.storage.GetWebhook(ctx, id)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to get created webhook", logger.Error(err))
+		return models.Webhook{}, err
+	}
+
+	return created, nil
+}
+
+// ListWebhooks возвращает подписки без секретов.
+func (s *Service) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const op = apiOp + "ListWebhooks"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	webhooks, err := s.storage.ListWebhooks(ctx)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list webhooks", logger.Error(err))
+		return nil, err
+	}
+
+	res := make([]models.Webhook, 0, len(webhooks))
+	for _, w := range webhooks {
+		res = append(res, withoutSecret(w))
+	}
+	return res, nil
+}
+
+// GetWebhook возвращает подписку без секрета.
+func (s *Service) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	const op = apiOp + "GetWebhook"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	webhook, err := s.storage.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrWebhookNotExists) {
+			return models.Webhook{}, models.ErrWebhookNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to get webhook", logger.Error(err))
+		return models.Webhook{}, err
+	}
+
+	return withoutSecret(webhook), nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её очередью доставки.
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	const op = apiOp + "DeleteWebhook"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if err := s.storage.DeleteWebhook(ctx, id); err != nil {
+		if errors.Is(err, sqlite.ErrWebhookNotExists) {
+			return models.ErrWebhookNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to delete webhook", logger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ListWebhookAttempts возвращает страницу журнала доставки подписки.
+func (s *Service) ListWebhookAttempts(
+	ctx context.Context,
+	webhookID int64,
+	limit, offset int,
+) ([]models.WebhookAttempt, int, error) {
+	const op = apiOp + "ListWebhookAttempts"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, 0, err
+	}
+
+	attempts, total, err := s.storage.ListWebhookAttempts(ctx, webhookID, limit, offset)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list webhook attempts", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return attempts, total, nil
+}
+
+// ListDeadDeliveries возвращает страницу недоставленных событий.
+func (s *Service) ListDeadDeliveries(ctx context.Context, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	const op = apiOp + "ListDeadDeliveries"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	deliveries, total, err := s.storage.ListDeadDeliveries(ctx, limit, offset)
+	if err != nil {
+		tracing.Error(span, err)
+		log.Error("failed to list dead deliveries", logger.Error(err))
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// RetryDelivery возвращает недоставленное событие в очередь.
+func (s *Service) RetryDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	const op = apiOp + "RetryDelivery"
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	delivery, err := s.storage.RetryDelivery(ctx, id)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrDeliveryNotExists) {
+			return models.WebhookDelivery{}, models.ErrDeliveryNotFound
+		}
+		tracing.Error(span, err)
+		log.Error("failed to retry delivery", logger.Error(err))
+		return models.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func withoutSecret(w models.Webhook) models.Webhook {
+	w.Secret = ""
+	return w
+}
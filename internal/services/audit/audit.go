@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+const auditOp = "services.audit."
+
+// Log дублирует журнал аудита строками JSON в конец файла. Сам журнал
+// пишет хранилище в транзакции изменения, а Log получает записи после её
+// фиксации через sqlite.Storage.OnAudit.
+type Log struct {
+	logger *slog.Logger
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewLog создаёт журнал. Пустой path отключает файловую копию.
+func NewLog(log *slog.Logger, path string) (*Log, error) {
+	const op = auditOp + "NewLog"
+
+	l := &Log{logger: log}
+	if path == "" {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("%s: failed to create audit log dir: %w", op, err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open audit log file: %w", op, err)
+	}
+	l.file = file
+
+	return l, nil
+}
+
+// Mirror дописывает запись в файл. Запись в SQLite к этому моменту уже
+// зафиксирована, поэтому ошибка файла только логируется.
+func (l *Log) Mirror(entry models.AuditEntry) {
+	const op = auditOp + "Mirror"
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = l.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		l.logger.Error("failed to write audit log file",
+			slog.String("op", op), slog.Int64("audit_id", entry.ID), logger.Error(err))
+	}
+}
+
+// Close закрывает файловую копию журнала.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// subscriberBuffer — сколько событий подписчик может не забирать, прежде
+// чем новые события для него начнут отбрасываться.
+const subscriberBuffer = 64
+
+// Bus рассылает события об изменении цитат подписчикам внутри процесса.
+// Медленный подписчик не блокирует публикацию: события сверх буфера ему не
+// доставляются, и он должен дочитать их из журнала изменений.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[chan models.QuoteEvent]struct{}
+	closed bool
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan models.QuoteEvent]struct{})}
+}
+
+func (b *Bus) Publish(event models.QuoteEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe возвращает канал событий и функцию отписки. Канал закрывается
+// при отписке и при Close; после Close канал возвращается уже закрытым.
+func (b *Bus) Subscribe() (<-chan models.QuoteEvent, func()) {
+	ch := make(chan models.QuoteEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close закрывает каналы всех подписчиков, чтобы потоковые ответы
+// завершились до остановки HTTP-сервера.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,345 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/interfaces"
+	"github.com/Grino777/quotes/internal/lib/logger"
+)
+
+// Заголовки запроса доставки. Подпись — HMAC-SHA256 от "<timestamp>.<тело>"
+// с секретом подписки в виде "sha256=<hex>"; метка времени в подписи не
+// даёт повторно отправить перехваченный запрос позже.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	// Тело ответа получателя не нужно, но его стоит дочитать для
+	// переиспользования соединения
+	maxResponseBody = 64 << 10
+	// Доля случайного разброса задержки между попытками
+	backoffJitter = 0.2
+)
+
+// Dispatcher доставляет события журнала изменений подписчикам. Очередь
+// хранится в SQLite, поэтому недоставленное переживает перезапуск.
+type Dispatcher struct {
+	logger  *slog.Logger
+	storage interfaces.WebhookStorage
+	events  interfaces.EventSubscriber
+	cfg     *config.WebhooksConfig
+	client  *http.Client
+}
+
+func NewDispatcher(
+	log *slog.Logger,
+	storage interfaces.WebhookStorage,
+	events interfaces.EventSubscriber,
+	cfg *config.WebhooksConfig,
+) *Dispatcher {
+	return &Dispatcher{
+		logger:  log,
+		storage: storage,
+		events:  events,
+		cfg:     cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: newTransport(cfg.AllowedNetworks),
+			// Перенаправление считается неудачной доставкой, адрес нужно исправить
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// errPrivateTarget возвращается при попытке подключиться к внутреннему адресу
+var errPrivateTarget = errors.New("webhook target resolves to a non-public address")
+
+// newTransport запрещает подключения к внутренним адресам, кроме сетей из
+// allowed. Проверка
+// выполняется после разрешения имени, поэтому её не обойти DNS rebinding
+// или перенаправлением. Прокси из окружения не используется: иначе
+// проверялся бы адрес прокси, а не получателя.
+func newTransport(allowed []netip.Prefix) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !models.TargetAllowed(addrPort.Addr(), allowed) {
+				return fmt.Errorf("%w: %s", errPrivateTarget, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// Run доставляет события до отмены ctx. Новое событие в шине будит
+// диспетчер сразу, повторные попытки проверяются раз в poll_interval.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wake <-chan models.QuoteEvent
+	if d.events != nil {
+		events, unsubscribe := d.events.Subscribe()
+		defer unsubscribe()
+		wake = events
+	}
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.Dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				wake = nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch раскладывает новые события по подпискам и выполняет все
+// доставки, время которых наступило.
+func (d *Dispatcher) Dispatch(ctx context.Context) {
+	log := d.logger.With(slog.String("op", "services.webhooks.Dispatch"))
+
+	for {
+		n, err := d.storage.EnqueueWebhookEvents(ctx, d.cfg.BatchSize)
+		if err != nil {
+			log.Error("failed to enqueue webhook events", logger.Error(err))
+			break
+		}
+		if n < d.cfg.BatchSize {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		deliveries, err := d.storage.DueWebhookDeliveries(ctx, time.Now(), d.cfg.BatchSize)
+		if err != nil {
+			log.Error("failed to get due webhook deliveries", logger.Error(err))
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		webhooks, err := d.storage.ListWebhooks(ctx)
+		if err != nil {
+			log.Error("failed to list webhooks", logger.Error(err))
+			return
+		}
+		byID := make(map[int64]models.Webhook, len(webhooks))
+		for _, w := range webhooks {
+			byID[w.ID] = w
+		}
+
+		d.deliverAll(ctx, log, byID, deliveries)
+
+		if len(deliveries) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliverAll доставляет выборку параллельно по подпискам: медленный или
+// недоступный получатель не задерживает остальных. Доставки одной подписки
+// идут по очереди, чтобы получатель видел события в исходном порядке.
+// Одновременно обслуживается не больше webhooks.concurrency подписок.
+func (d *Dispatcher) deliverAll(
+	ctx context.Context,
+	log *slog.Logger,
+	byID map[int64]models.Webhook,
+	deliveries []models.WebhookDelivery,
+) {
+	var order []int64
+	queues := make(map[int64][]models.WebhookDelivery)
+	for _, delivery := range deliveries {
+		// Подписку удалили после выборки, её очередь удалена вместе с ней
+		if _, ok := byID[delivery.WebhookID]; !ok {
+			continue
+		}
+		if _, ok := queues[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		queues[delivery.WebhookID] = append(queues[delivery.WebhookID], delivery)
+	}
+
+	sem := make(chan struct{}, max(d.cfg.Concurrency, 1))
+	var wg sync.WaitGroup
+	for _, id := range order {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(w models.Webhook, queue []models.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for _, delivery := range queue {
+				if ctx.Err() != nil {
+					return
+				}
+				d.deliver(ctx, log, w, delivery)
+			}
+		}(byID[id], queues[id])
+	}
+	wg.Wait()
+}
+
+// deliver выполняет одну попытку и сохраняет её результат. Попытка,
+// прерванная остановкой сервиса, не учитывается.
+func (d *Dispatcher) deliver(ctx context.Context, log *slog.Logger, w models.Webhook, delivery models.WebhookDelivery) {
+	log = log.With(
+		slog.Int64("webhook_id", w.ID),
+		slog.Int64("delivery_id", delivery.ID),
+		slog.String("event", delivery.EventType),
+	)
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, w, delivery, start)
+	if ctx.Err() != nil {
+		return
+	}
+
+	attempt := models.WebhookAttempt{
+		Attempt:    delivery.Attempts + 1,
+		StatusCode: statusCode,
+		DurationMs: time.Since(start).Milliseconds(),
+		Time:       start,
+	}
+	delivery.Attempts++
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		log.Debug("webhook delivered", slog.Int("attempt", attempt.Attempt))
+	} else {
+		attempt.Error = err.Error()
+		delivery.LastError = attempt.Error
+		if delivery.Attempts >= d.cfg.MaxAttempts {
+			delivery.Status = models.DeliveryDead
+			log.Warn("webhook delivery moved to dead letters",
+				slog.Int("attempts", delivery.Attempts), logger.Error(err))
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+			log.Debug("webhook delivery failed, will retry",
+				slog.Int("attempt", attempt.Attempt), slog.Time("next_attempt_at", delivery.NextAttemptAt),
+				logger.Error(err))
+		}
+	}
+
+	if err := d.storage.RecordWebhookAttempt(ctx, delivery, attempt); err != nil {
+		log.Error("failed to record webhook attempt", logger.Error(err))
+	}
+}
+
+// send отправляет событие и возвращает код ответа. Успехом считается
+// только ответ 2xx.
+func (d *Dispatcher) send(ctx context.Context, w models.Webhook, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "quotes-webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff возвращает задержку перед следующей попыткой: backoff_base,
+// удваивающийся с каждой попыткой до backoff_max, со случайным разбросом.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempts && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.cfg.BackoffMax)
+
+	return delay + time.Duration(rand.Float64()*backoffJitter*float64(delay))
+}
+
+// Sign возвращает значение заголовка X-Webhook-Signature для тела body,
+// отправленного в момент timestamp (Unix, секунды).
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса доставки на стороне получателя. Запрос
+// старше tolerance отклоняется, чтобы его нельзя было воспроизвести.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return errors.New("timestamp outside tolerance")
+	}
+
+	expected := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(HeaderSignature))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
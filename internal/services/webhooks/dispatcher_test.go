@@ -0,0 +1,364 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/storage/sqlite"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func testConfig() *config.WebhooksConfig {
+	return &config.WebhooksConfig{
+		Enabled:      true,
+		PollInterval: time.Hour,
+		Timeout:      5 * time.Second,
+		MaxAttempts:  3,
+		BackoffBase:  50 * time.Millisecond,
+		BackoffMax:   100 * time.Millisecond,
+		BatchSize:    50,
+		Concurrency:  4,
+		// httptest.Server слушает loopback
+		AllowedNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+	}
+}
+
+func newTestStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage := sqlite.NewStorage(log, &config.SQLiteConfig{Addr: filepath.Join(t.TempDir(), "quotes.sqlite")})
+	if err := storage.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = storage.Close() })
+	return storage
+}
+
+func newTestDispatcher(t *testing.T, storage *sqlite.Storage, cfg *config.WebhooksConfig) *Dispatcher {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewDispatcher(log, storage, nil, cfg)
+}
+
+func subscribe(t *testing.T, storage *sqlite.Storage, url string) int64 {
+	t.Helper()
+
+	id, err := storage.CreateWebhook(context.Background(), models.Webhook{
+		URL:    url,
+		Events: []string{models.EventQuoteCreated},
+		Secret: testSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func createQuote(t *testing.T, storage *sqlite.Storage, text string) {
+	t.Helper()
+
+	if _, err := storage.CreateQuote(context.Background(), models.Quote{Author: "seneca", Quote: text}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func attempts(t *testing.T, storage *sqlite.Storage, webhookID int64) []models.WebhookAttempt {
+	t.Helper()
+
+	res, _, err := storage.ListWebhookAttempts(context.Background(), webhookID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestDispatchSignsDelivery(t *testing.T) {
+	storage := newTestStorage(t)
+
+	verified := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderEvent) != models.EventQuoteCreated {
+			verified <- errors.New("unexpected event header " + r.Header.Get(HeaderEvent))
+		} else {
+			verified <- Verify(testSecret, r.Header, body, time.Minute)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	id := subscribe(t, storage, srv.URL)
+	createQuote(t, storage, "signed")
+
+	newTestDispatcher(t, storage, testConfig()).Dispatch(context.Background())
+
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Fatalf("signature verification failed: %v", err)
+		}
+	default:
+		t.Fatal("receiver was not called")
+	}
+
+	got := attempts(t, storage, id)
+	if len(got) != 1 || got[0].StatusCode != http.StatusNoContent || got[0].Error != "" {
+		t.Fatalf("attempts = %+v, want one successful attempt", got)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	body := []byte(`{"type":"quote.created"}`)
+	now := time.Now().Unix()
+
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now, 10))
+	header.Set(HeaderSignature, Sign(testSecret, now, body))
+
+	if err := Verify(testSecret, header, body, time.Minute); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := Verify("another-secret-value", header, body, time.Minute); err == nil {
+		t.Error("signature with wrong secret accepted")
+	}
+	if err := Verify(testSecret, header, []byte(`{"type":"quote.deleted"}`), time.Minute); err == nil {
+		t.Error("signature of modified body accepted")
+	}
+
+	old := now - 3600
+	header.Set(HeaderTimestamp, strconv.FormatInt(old, 10))
+	header.Set(HeaderSignature, Sign(testSecret, old, body))
+	if err := Verify(testSecret, header, body, time.Minute); err == nil {
+		t.Error("stale timestamp accepted")
+	}
+}
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	storage := newTestStorage(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	id := subscribe(t, storage, srv.URL)
+	createQuote(t, storage, "retried")
+
+	cfg := testConfig()
+	d := newTestDispatcher(t, storage, cfg)
+	ctx := context.Background()
+
+	start := time.Now()
+	d.Dispatch(ctx)
+
+	pending, err := storage.DueWebhookDeliveries(ctx, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].Status != models.DeliveryPending {
+		t.Fatalf("after first attempt deliveries = %+v, want one pending with 1 attempt", pending)
+	}
+	if delay := pending[0].NextAttemptAt.Sub(start); delay < cfg.BackoffBase-time.Millisecond {
+		t.Errorf("next attempt in %v, want at least backoff base %v", delay, cfg.BackoffBase)
+	}
+
+	// Повтор до истечения задержки не выполняется
+	d.Dispatch(ctx)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("receiver called %d times before backoff elapsed, want 1", n)
+	}
+
+	waitFor(t, func() bool {
+		d.Dispatch(ctx)
+		return calls.Load() >= 3
+	})
+
+	got := attempts(t, storage, id)
+	if len(got) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(got))
+	}
+	// Журнал отдаёт новые попытки первыми
+	if got[0].StatusCode != http.StatusOK || got[1].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("attempts = %+v, want two 503 then 200", got)
+	}
+}
+
+func TestDispatchMovesToDeadLetters(t *testing.T) {
+	storage := newTestStorage(t)
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	id := subscribe(t, storage, srv.URL)
+	createQuote(t, storage, "dead")
+
+	cfg := testConfig()
+	d := newTestDispatcher(t, storage, cfg)
+	ctx := context.Background()
+
+	var dead []models.WebhookDelivery
+	waitFor(t, func() bool {
+		d.Dispatch(ctx)
+		var err error
+		dead, _, err = storage.ListDeadDeliveries(ctx, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(dead) > 0
+	})
+
+	if dead[0].Attempts != cfg.MaxAttempts || dead[0].Status != models.DeliveryDead {
+		t.Errorf("dead delivery = %+v, want %d attempts", dead[0], cfg.MaxAttempts)
+	}
+	if dead[0].LastError == "" {
+		t.Error("dead delivery has no last error")
+	}
+
+	// Недоставленное больше не отправляется
+	d.Dispatch(ctx)
+	if n := calls.Load(); n != int32(cfg.MaxAttempts) {
+		t.Errorf("receiver called %d times, want %d", n, cfg.MaxAttempts)
+	}
+	if got := attempts(t, storage, id); len(got) != cfg.MaxAttempts {
+		t.Errorf("got %d attempts, want %d", len(got), cfg.MaxAttempts)
+	}
+}
+
+func TestDispatchDeliversSubscriptionsConcurrently(t *testing.T) {
+	storage := newTestStorage(t)
+
+	fastDone := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Последовательная доставка дождалась бы здесь таймаута
+		select {
+		case <-fastDone:
+			w.WriteHeader(http.StatusOK)
+		case <-time.After(2 * time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		close(fastDone)
+	}))
+	defer fast.Close()
+
+	slowID := subscribe(t, storage, slow.URL)
+	subscribe(t, storage, fast.URL)
+	createQuote(t, storage, "concurrent")
+
+	newTestDispatcher(t, storage, testConfig()).Dispatch(context.Background())
+
+	got := attempts(t, storage, slowID)
+	if len(got) != 1 || got[0].StatusCode != http.StatusOK {
+		t.Fatalf("slow receiver attempts = %+v, want one 200", got)
+	}
+}
+
+func TestDispatchRefusesPrivateTargets(t *testing.T) {
+	for name, allowed := range map[string][]netip.Prefix{
+		"default":       nil,
+		"other network": {netip.MustParsePrefix("10.0.0.0/8")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			storage := newTestStorage(t)
+
+			var called atomic.Bool
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called.Store(true)
+			}))
+			defer srv.Close()
+
+			id := subscribe(t, storage, srv.URL)
+			createQuote(t, storage, "ssrf")
+
+			cfg := testConfig()
+			cfg.AllowedNetworks = allowed
+			newTestDispatcher(t, storage, cfg).Dispatch(context.Background())
+
+			if called.Load() {
+				t.Fatal("dispatcher connected to a loopback address")
+			}
+			got := attempts(t, storage, id)
+			if len(got) != 1 || got[0].StatusCode != 0 || !strings.Contains(got[0].Error, errPrivateTarget.Error()) {
+				t.Fatalf("attempts = %+v, want one refused attempt", got)
+			}
+		})
+	}
+}
+
+func TestDispatchAllowsConfiguredNetworks(t *testing.T) {
+	storage := newTestStorage(t)
+
+	var called atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	id := subscribe(t, storage, srv.URL)
+	createQuote(t, storage, "allowed")
+
+	cfg := testConfig()
+	cfg.AllowedNetworks = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+	newTestDispatcher(t, storage, cfg).Dispatch(context.Background())
+
+	if !called.Load() {
+		t.Fatal("receiver in an allowed network was not called")
+	}
+	got := attempts(t, storage, id)
+	if len(got) != 1 || got[0].StatusCode != http.StatusNoContent || got[0].Error != "" {
+		t.Fatalf("attempts = %+v, want one successful attempt", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{cfg: &config.WebhooksConfig{BackoffBase: time.Second, BackoffMax: 5 * time.Second}}
+
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		got := d.backoff(attempts)
+		high := time.Duration(float64(want) * (1 + backoffJitter))
+		if got < want || got > high {
+			t.Errorf("backoff(%d) = %v, want %v..%v", attempts, got, want, high)
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/clientip"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/requestid"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// Время, передаваемое из Go (аудит, расписание webhooks), хранится в UTC с
+// миллисекундами, как и now, чтобы строки сравнивались в хронологическом порядке.
+const timeFormat = "2006-01-02 15:04:05.000"
+
+// OnAudit задаёт функцию, которая получает записи журнала аудита после
+// фиксации транзакции, например для копии журнала в файле. Вызывается до
+// начала обслуживания запросов.
+func (s *Storage) OnAudit(fn func(models.AuditEntry)) {
+	s.onAudit = fn
+}
+
+const auditStmt = `INSERT INTO audit_log
+	(time, action, entity_id, actor, client_ip, request_id, before, after)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+// appendAudit пишет действие в журнал аудита. Как и recordRevision,
+// вызывается в транзакции изменения: если запись не удалась, изменение
+// откатывается. before и after — объекты до и после изменения, nil
+// означает их отсутствие. Автор, IP клиента и идентификатор запроса
+// берутся из контекста.
+func appendAudit(
+	ctx context.Context,
+	q querier,
+	op string,
+	action string,
+	entityID int64,
+	before, after any,
+) (models.AuditEntry, error) {
+	entry := models.AuditEntry{
+		Time:      time.Now().UTC().Truncate(time.Millisecond),
+		Action:    action,
+		EntityID:  entityID,
+		Actor:     actor.FromContext(ctx),
+		ClientIP:  clientip.FromContext(ctx),
+		RequestID: requestid.FromContext(ctx),
+	}
+
+	var err error
+	if entry.Before, err = marshalAudit(before); err != nil {
+		return entry, fmt.Errorf("%s: failed to encode audit before: %w", op, err)
+	}
+	if entry.After, err = marshalAudit(after); err != nil {
+		return entry, fmt.Errorf("%s: failed to encode audit after: %w", op, err)
+	}
+
+	result, err := q.ExecContext(ctx, auditStmt,
+		entry.Time.Format(timeFormat),
+		entry.Action,
+		sql.NullInt64{Int64: entry.EntityID, Valid: entry.EntityID != 0},
+		entry.Actor,
+		sql.NullString{String: entry.ClientIP, Valid: entry.ClientIP != ""},
+		sql.NullString{String: entry.RequestID, Valid: entry.RequestID != ""},
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+	)
+	if err != nil {
+		return entry, fmt.Errorf("%s: failed to append audit entry: %w", op, err)
+	}
+
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return entry, fmt.Errorf("%s: failed to retrieve audit entry ID: %w", op, err)
+	}
+
+	return entry, nil
+}
+
+// mirrorAudit передаёт зафиксированные записи в OnAudit.
+func (s *Storage) mirrorAudit(entries ...models.AuditEntry) {
+	if s.onAudit == nil {
+		return
+	}
+	for _, entry := range entries {
+		s.onAudit(entry)
+	}
+}
+
+func marshalAudit(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// ListAudit возвращает страницу журнала аудита, новые записи первыми.
+func (s *Storage) ListAudit(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, int, error) {
+	const op = opQuotes + "ListAudit"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	var (
+		conds []string
+		args  []any
+	)
+	if filter.Actor != "" {
+		conds = append(conds, `actor = ?`)
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conds = append(conds, `action = ?`)
+		args = append(args, filter.Action)
+	}
+	if filter.EntityID != 0 {
+		conds = append(conds, `entity_id = ?`)
+		args = append(args, filter.EntityID)
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, `time >= ?`)
+		args = append(args, filter.From.UTC().Format(timeFormat))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, `time < ?`)
+		args = append(args, filter.To.UTC().Format(timeFormat))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+
+	stmt := `SELECT id, time, action, entity_id, actor, client_ip, request_id, before, after
+	FROM audit_log` + where + ` ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var total int
+	if err := s.client.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to count audit entries: %w", op, err))
+	}
+
+	rows, err := s.client.QueryContext(ctx, stmt, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to query audit entries: %w", op, err))
+	}
+	defer rows.Close()
+
+	entries := make([]models.AuditEntry, 0, filter.Limit)
+
+	for rows.Next() {
+		var (
+			e                   models.AuditEntry
+			entityID            sql.NullInt64
+			clientIP, requestID sql.NullString
+			before, after       sql.NullString
+		)
+		if err := rows.Scan(&e.ID, &e.Time, &e.Action, &entityID, &e.Actor,
+			&clientIP, &requestID, &before, &after); err != nil {
+			return nil, 0, logged(span, log, fmt.Errorf("%s: failed to scan audit entry: %w", op, err))
+		}
+
+		e.EntityID = entityID.Int64
+		e.ClientIP = clientIP.String
+		e.RequestID = requestID.String
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
+	}
+
+	return entries, total, nil
+}
+
+func nullJSON(data []byte) sql.NullString {
+	return sql.NullString{String: string(data), Valid: len(data) > 0}
+}
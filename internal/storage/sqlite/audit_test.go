@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewStorage(log, &config.SQLiteConfig{Addr: filepath.Join(t.TempDir(), "quotes.sqlite")})
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestAuditFailureRollsBackMutation(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "before"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Запись в журнал падает так же, как при сбое диска или ограничения
+	if _, err := s.client.ExecContext(ctx, `CREATE TRIGGER audit_fail BEFORE INSERT ON audit_log
+	BEGIN SELECT RAISE(ABORT, 'audit unavailable'); END`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.UpdateQuote(ctx, int(id), models.Quote{Author: "seneca", Quote: "after"}, 0); err == nil {
+		t.Fatal("update succeeded without audit entry")
+	}
+
+	got, err := s.GetQuote(ctx, int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Quote != "before" || got.Version != 1 {
+		t.Errorf("quote = %+v, want unchanged", got)
+	}
+
+	history, _, err := s.QuoteHistory(ctx, int(id), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Errorf("got %d revisions, want 1", len(history))
+	}
+}
+
+func TestRestoreAuditRecordsTrashedQuote(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var mirrored []models.AuditEntry
+	s.OnAudit(func(e models.AuditEntry) { mirrored = append(mirrored, e) })
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "trashed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteQuote(ctx, int(id), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreQuote(ctx, int(id)); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, total, err := s.ListAudit(ctx, models.AuditFilter{Action: models.AuditQuoteRestore, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("got %d restore entries, want 1", total)
+	}
+
+	var before, after models.Quote
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		t.Fatalf("restore entry has no before: %v", err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		t.Fatalf("restore entry has no after: %v", err)
+	}
+	if before.Quote != "trashed" || after.Quote != "trashed" {
+		t.Errorf("before = %+v, after = %+v", before, after)
+	}
+
+	if len(mirrored) != 3 || mirrored[2].ID != entries[0].ID {
+		t.Errorf("mirrored %d entries, want create, delete and restore", len(mirrored))
+	}
+}
+
+func TestBatchAuditFollowsTransaction(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "existing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops := []models.BatchOperation{
+		{Op: models.BatchCreate, Quote: models.Quote{Author: "seneca", Quote: "new"}},
+		{Op: models.BatchDelete, ID: int(id) + 100},
+		{Op: models.BatchUpdate, ID: int(id), Quote: models.Quote{Author: "seneca", Quote: "updated"}},
+	}
+
+	countAudit := func() int {
+		_, total, err := s.ListAudit(ctx, models.AuditFilter{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return total
+	}
+	initial := countAudit()
+
+	if _, committed, err := s.ApplyBatch(ctx, ops, models.BatchAtomic); err != nil || committed {
+		t.Fatalf("atomic batch: committed = %v, err = %v", committed, err)
+	}
+	if n := countAudit(); n != initial {
+		t.Errorf("rolled back batch left %d audit entries", n-initial)
+	}
+
+	if _, committed, err := s.ApplyBatch(ctx, ops, models.BatchBestEffort); err != nil || !committed {
+		t.Fatalf("best effort batch: committed = %v, err = %v", committed, err)
+	}
+	if n := countAudit(); n != initial+2 {
+		t.Errorf("best effort batch wrote %d audit entries, want 2", n-initial)
+	}
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// errBatchFailed откатывает транзакцию пакета в режиме atomic.
+var errBatchFailed = errors.New("batch operation failed")
+
+// ApplyBatch выполняет операции в одной транзакции. В режиме atomic первая
+// ошибка откатывает весь пакет, в best_effort каждая операция выполняется
+// в своей точке сохранения и при ошибке откатывается только она.
+// Возвращает результаты выполненных операций (в atomic — до первой
+// ошибки включительно) и признак фиксации транзакции. Ошибка возвращается,
+// только если пакет прерван неожиданной ошибкой базы.
+func (s *Storage) ApplyBatch(
+	ctx context.Context,
+	ops []models.BatchOperation,
+	mode models.BatchMode,
+) ([]models.BatchOutcome, bool, error) {
+	const op = opQuotes + "ApplyBatch"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	var (
+		outcomes []models.BatchOutcome
+		entries  []models.AuditEntry
+	)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		outcomes = make([]models.BatchOutcome, 0, len(ops))
+
+		for _, o := range ops {
+			if mode == models.BatchBestEffort {
+				if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_op`); err != nil {
+					return fmt.Errorf("%s: failed to create savepoint: %w", op, err)
+				}
+			}
+
+			outcome, entry := applyBatchOp(ctx, tx, op, o)
+			outcomes = append(outcomes, outcome)
+			if outcome.Err == nil {
+				entries = append(entries, entry)
+			}
+
+			if outcome.Err != nil && !expectedErr(outcome.Err) {
+				return outcome.Err
+			}
+			if outcome.Err != nil && mode == models.BatchAtomic {
+				return errBatchFailed
+			}
+
+			if mode == models.BatchBestEffort {
+				if outcome.Err != nil {
+					if _, err := tx.ExecContext(ctx, `ROLLBACK TO batch_op`); err != nil {
+						return fmt.Errorf("%s: failed to roll back to savepoint: %w", op, err)
+					}
+				}
+				if _, err := tx.ExecContext(ctx, `RELEASE batch_op`); err != nil {
+					return fmt.Errorf("%s: failed to release savepoint: %w", op, err)
+				}
+			}
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errBatchFailed):
+		return outcomes, false, nil
+	case err != nil:
+		return nil, false, logged(span, log, err)
+	}
+
+	s.mirrorAudit(entries...)
+	return outcomes, true, nil
+}
+
+// batchAudit — действия журнала аудита для операций пакета.
+var batchAudit = map[models.BatchOp]string{
+	models.BatchCreate: models.AuditQuoteCreate,
+	models.BatchUpdate: models.AuditQuoteUpdate,
+	models.BatchDelete: models.AuditQuoteDelete,
+}
+
+// applyBatchOp выполняет операцию и пишет её в журнал аудита в транзакции
+// пакета, поэтому откат операции или всего пакета отменяет и запись.
+func applyBatchOp(ctx context.Context, tx *sql.Tx, op string, o models.BatchOperation) (models.BatchOutcome, models.AuditEntry) {
+	outcome := models.BatchOutcome{ID: o.ID}
+
+	var before any
+	if o.Op != models.BatchCreate {
+		quote, err := getQuote(ctx, tx, op, o.ID)
+		if err != nil {
+			outcome.Err = err
+			return outcome, models.AuditEntry{}
+		}
+		before = quote
+	}
+
+	switch o.Op {
+	case models.BatchCreate:
+		id, err := createQuote(ctx, tx, op, o.Quote)
+		if err != nil {
+			outcome.Err = err
+			return outcome, models.AuditEntry{}
+		}
+		outcome.ID = int(id)
+	case models.BatchUpdate:
+		outcome.Err = updateQuote(ctx, tx, op, o.ID, o.Quote, o.Version, models.RevisionUpdate)
+	case models.BatchDelete:
+		outcome.Err = deleteQuote(ctx, tx, op, o.ID, o.Version)
+	}
+	if outcome.Err != nil {
+		return outcome, models.AuditEntry{}
+	}
+
+	var after any
+	if o.Op != models.BatchDelete {
+		quote, err := getQuote(ctx, tx, op, outcome.ID)
+		if err != nil {
+			outcome.Err = err
+			return outcome, models.AuditEntry{}
+		}
+		outcome.After = &quote
+		after = quote
+	}
+
+	entry, err := appendAudit(ctx, tx, op, batchAudit[o.Op], int64(outcome.ID), before, after)
+	if err != nil {
+		outcome.Err = err
+	}
+	return outcome, entry
+}
+
+// expectedErr — ошибки операции, после которых пакет можно продолжать.
+func expectedErr(err error) bool {
+	return errors.Is(err, ErrAlreadyExist) ||
+		errors.Is(err, ErrQuoteNotExists) ||
+		errors.Is(err, ErrVersionMismatch)
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// Журналом изменений служит история ревизий: каждая запись пишется в одной
+// транзакции с изменением, а её идентификатор монотонно растёт.
+const eventColumns = `id, quote_id, action, author, quote, actor, created_at`
+
+const eventsWhere = `action <> 'snapshot'`
+
+func scanEvent(row rowScanner) (models.QuoteEvent, error) {
+	var (
+		e      models.QuoteEvent
+		action models.RevisionAction
+	)
+	if err := row.Scan(&e.ID, &e.QuoteID, &action,
+		&e.Quote.Author, &e.Quote.Quote, &e.Actor, &e.Time); err != nil {
+		return e, err
+	}
+	e.Type, _ = models.EventType(action)
+	return e, nil
+}
+
+// QuoteEvents возвращает до limit событий с идентификатором больше afterID
+// в порядке возрастания.
+func (s *Storage) QuoteEvents(ctx context.Context, afterID int64, limit int) ([]models.QuoteEvent, error) {
+	const op = opQuotes + "QuoteEvents"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(eventsStmt)...)
+	defer span.End()
+
+	events, err := queryEvents(ctx, s.client, afterID, limit)
+	if err != nil {
+		return nil, logged(span, log, fmt.Errorf("%s: %w", op, err))
+	}
+
+	return events, nil
+}
+
+const eventsStmt = `SELECT ` + eventColumns + ` FROM quote_revisions
+	WHERE id > ? AND ` + eventsWhere + ` ORDER BY id LIMIT ?`
+
+func queryEvents(ctx context.Context, q querier, afterID int64, limit int) ([]models.QuoteEvent, error) {
+	rows, err := q.QueryContext(ctx, eventsStmt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.QuoteEvent, 0, limit)
+
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to process query result: %w", err)
+	}
+
+	return events, nil
+}
+
+// LastQuoteEvent возвращает последнее событие цитаты.
+func (s *Storage) LastQuoteEvent(ctx context.Context, quoteID int) (models.QuoteEvent, error) {
+	const op = opQuotes + "LastQuoteEvent"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT ` + eventColumns + ` FROM quote_revisions
+	WHERE quote_id = ? AND ` + eventsWhere + ` ORDER BY id DESC LIMIT 1`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	e, err := scanEvent(s.client.QueryRowContext(ctx, stmt, quoteID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.QuoteEvent{}, ErrQuoteNotExists
+		}
+		return models.QuoteEvent{}, logged(span, log, fmt.Errorf("%s: failed to scan event: %w", op, err))
+	}
+
+	return e, nil
+}
+
+// LastEventID возвращает идентификатор последнего события или 0.
+func (s *Storage) LastEventID(ctx context.Context) (int64, error) {
+	const op = opQuotes + "LastEventID"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT COALESCE(MAX(id), 0) FROM quote_revisions`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var id int64
+	if err := s.client.QueryRowContext(ctx, stmt).Scan(&id); err != nil {
+		return 0, logged(span, log, fmt.Errorf("%s: failed to read last event id: %w", op, err))
+	}
+
+	return id, nil
+}
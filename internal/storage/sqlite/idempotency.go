@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+var (
+	// Ключ уже использован с запросом с другим хешем
+	ErrIdempotencyMismatch = errors.New("idempotency key used with another request")
+	// Ответ по ключу ещё не сохранён
+	ErrIdempotencyInProgress = errors.New("idempotent request in progress")
+)
+
+// BeginIdempotent занимает ключ автора из контекста на ttl. Если ключ
+// свободен, возвращается nil, и вызывающий должен выполнить запрос и
+// сохранить ответ через SaveIdempotent или освободить ключ через
+// ReleaseIdempotent. Если по ключу уже сохранён ответ на тот же запрос,
+// он возвращается для повтора. Пока ответа нет, ключ арендован на lease:
+// если процесс упал, не освободив ключ, после аренды тот же запрос
+// занимает его снова. Просроченные ключи удаляются по пути.
+func (s *Storage) BeginIdempotent(
+	ctx context.Context,
+	key, requestHash string,
+	ttl, lease time.Duration,
+) (*models.IdempotentResponse, error) {
+	const op = opQuotes + "BeginIdempotent"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `INSERT INTO idempotency_keys (actor, key, request_hash, created_at, expires_at, locked_until)
+	VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (actor, key) DO NOTHING`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	who := actor.FromContext(ctx)
+	now := time.Now().UTC()
+
+	var stored *models.IdempotentResponse
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM idempotency_keys WHERE expires_at <= ?`, now.Format(timeFormat)); err != nil {
+			return fmt.Errorf("%s: failed to delete expired keys: %w", op, err)
+		}
+
+		result, err := tx.ExecContext(ctx, stmt,
+			who, key, requestHash, now.Format(timeFormat), now.Add(ttl).Format(timeFormat),
+			now.Add(lease).Format(timeFormat))
+		if err != nil {
+			return fmt.Errorf("%s: failed to insert key: %w", op, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		} else if n > 0 {
+			return nil
+		}
+
+		// Аренда ключа без ответа истекла: прежний запрос не завершился
+		result, err = tx.ExecContext(ctx, `UPDATE idempotency_keys SET locked_until = ?
+		WHERE actor = ? AND key = ? AND request_hash = ? AND status IS NULL AND locked_until <= ?`,
+			now.Add(lease).Format(timeFormat), who, key, requestHash, now.Format(timeFormat))
+		if err != nil {
+			return fmt.Errorf("%s: failed to reclaim key: %w", op, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		} else if n > 0 {
+			return nil
+		}
+
+		var (
+			hash    string
+			status  sql.NullInt64
+			headers sql.NullString
+			body    []byte
+		)
+		if err := tx.QueryRowContext(ctx,
+			`SELECT request_hash, status, headers, body FROM idempotency_keys WHERE actor = ? AND key = ?`,
+			who, key).Scan(&hash, &status, &headers, &body); err != nil {
+			return fmt.Errorf("%s: failed to read key: %w", op, err)
+		}
+
+		switch {
+		case hash != requestHash:
+			return ErrIdempotencyMismatch
+		case !status.Valid:
+			return ErrIdempotencyInProgress
+		}
+
+		stored = &models.IdempotentResponse{Status: int(status.Int64), Body: body}
+		if err := json.Unmarshal([]byte(headers.String), &stored.Header); err != nil {
+			return fmt.Errorf("%s: failed to decode headers: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrIdempotencyMismatch) || errors.Is(err, ErrIdempotencyInProgress) {
+			return nil, err
+		}
+		return nil, logged(span, log, err)
+	}
+
+	return stored, nil
+}
+
+// SaveIdempotent сохраняет ответ на запрос по занятому ключу.
+func (s *Storage) SaveIdempotent(ctx context.Context, key string, resp models.IdempotentResponse) error {
+	const op = opQuotes + "SaveIdempotent"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE actor = ? AND key = ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return logged(span, log, fmt.Errorf("%s: failed to encode headers: %w", op, err))
+	}
+
+	if _, err := s.client.ExecContext(ctx, stmt,
+		resp.Status, string(headers), resp.Body, actor.FromContext(ctx), key); err != nil {
+		return logged(span, log, fmt.Errorf("%s: failed to save response: %w", op, err))
+	}
+
+	return nil
+}
+
+// ReleaseIdempotent освобождает ключ, ответ по которому не сохранён, чтобы
+// запрос можно было повторить.
+func (s *Storage) ReleaseIdempotent(ctx context.Context, key string) error {
+	const op = opQuotes + "ReleaseIdempotent"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `DELETE FROM idempotency_keys WHERE actor = ? AND key = ? AND status IS NULL`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	if _, err := s.client.ExecContext(ctx, stmt, actor.FromContext(ctx), key); err != nil {
+		return logged(span, log, fmt.Errorf("%s: failed to release key: %w", op, err))
+	}
+
+	return nil
+}
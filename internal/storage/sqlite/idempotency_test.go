@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+// Ключ без ответа занят, пока действует аренда, а после неё тот же запрос
+// занимает его снова: процесс, выполнявший запрос, мог упасть.
+func TestBeginIdempotentReclaimsExpiredLease(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if _, err := s.BeginIdempotent(ctx, "key", "hash", time.Hour, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.BeginIdempotent(ctx, "key", "hash", time.Hour, time.Second); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Fatalf("err = %v, want ErrIdempotencyInProgress while leased", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := s.BeginIdempotent(ctx, "key", "other", time.Hour, time.Second); !errors.Is(err, ErrIdempotencyMismatch) {
+		t.Fatalf("err = %v, want ErrIdempotencyMismatch for another request", err)
+	}
+	stored, err := s.BeginIdempotent(ctx, "key", "hash", time.Hour, time.Second)
+	if err != nil || stored != nil {
+		t.Fatalf("got %v, %v, want the expired key reclaimed", stored, err)
+	}
+	if _, err := s.BeginIdempotent(ctx, "key", "hash", time.Hour, time.Second); !errors.Is(err, ErrIdempotencyInProgress) {
+		t.Fatalf("err = %v, want ErrIdempotencyInProgress under the new lease", err)
+	}
+
+	// Сохранённый ответ не отбирается и после аренды
+	resp := models.IdempotentResponse{Status: 201, Header: map[string]string{"Location": "/quotes/1"}, Body: []byte("{}")}
+	if err := s.SaveIdempotent(ctx, "key", resp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.client.ExecContext(ctx, `UPDATE idempotency_keys SET locked_until = created_at`); err != nil {
+		t.Fatal(err)
+	}
+	stored, err = s.BeginIdempotent(ctx, "key", "hash", time.Hour, time.Second)
+	if err != nil || stored == nil || stored.Status != 201 {
+		t.Fatalf("got %+v, %v, want the saved response replayed", stored, err)
+	}
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// LikeQuote ставит цитате отметку клиента subject. Повторная отметка
+// ничего не меняет.
+func (s *Storage) LikeQuote(ctx context.Context, id int, subject string) error {
+	const op = opQuotes + "LikeQuote"
+
+	stmt := `INSERT INTO quote_likes (quote_id, actor, created_at) VALUES (?, ?, ` + now + `)
+	ON CONFLICT (quote_id, actor) DO NOTHING`
+
+	return s.changeLike(ctx, op, stmt, models.AuditQuoteLike, id, subject)
+}
+
+// UnlikeQuote снимает отметку клиента subject, если она была.
+func (s *Storage) UnlikeQuote(ctx context.Context, id int, subject string) error {
+	const op = opQuotes + "UnlikeQuote"
+
+	stmt := `DELETE FROM quote_likes WHERE quote_id = ? AND actor = ?`
+
+	return s.changeLike(ctx, op, stmt, models.AuditQuoteUnlike, id, subject)
+}
+
+// changeLike выполняет stmt и, если отметка изменилась, пишет action в
+// журнал аудита в той же транзакции.
+func (s *Storage) changeLike(ctx context.Context, op, stmt, action string, id int, subject string) error {
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var entries []models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// Цитаты в корзине отметить нельзя
+		if _, err := activeContent(ctx, tx, op, id); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, stmt, id, subject)
+		if err != nil {
+			return fmt.Errorf("%s: failed to change like: %w", op, err)
+		}
+		changed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		}
+		// Повторная отметка или снятие несуществующей ничего не меняют
+		if changed == 0 {
+			return nil
+		}
+
+		like := map[string]any{"quote_id": id, "actor": subject}
+		before, after := any(nil), any(like)
+		if action == models.AuditQuoteUnlike {
+			before, after = like, nil
+		}
+		entry, err := appendAudit(ctx, tx, op, action, int64(id), before, after)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entries...)
+	return nil
+}
+
+// PopularQuotes возвращает страницу отмеченных цитат по убыванию
+// популярности, с фильтром по автору. Вес отметки убывает с возрастом как
+// exp(-ln2·age/halfLife): свежая весит 1, через halfLife — 1/2, через
+// 2·halfLife — 1/4.
+func (s *Storage) PopularQuotes(
+	ctx context.Context,
+	halfLife time.Duration,
+	filter models.QuoteFilter,
+) ([]models.Quote, int, error) {
+	const op = opQuotes + "PopularQuotes"
+
+	// halfLife берётся из конфигурации, поэтому подставляется в запрос
+	// литералом: listPage передаёт одни и те же аргументы в выборку и подсчёт.
+	// exp регистрируется драйвером, см. driverName
+	score := fmt.Sprintf(`(SELECT SUM(exp((julianday('now') - julianday(l.created_at)) * 86400.0 * %g))
+	FROM quote_likes l WHERE l.quote_id = quotes.id)`, -math.Ln2/halfLife.Seconds())
+
+	where := notDeleted + ` AND likes > 0`
+	var args []any
+	if filter.Author != "" {
+		where += ` AND author = ?`
+		args = append(args, filter.Author)
+	}
+
+	return s.listPage(ctx, op, where, args, score+` DESC, likes DESC, id`, filter)
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+func TestLikesKeyedBySubject(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "liked"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, subject := range []string{"alice", "alice", "bob"} {
+		if err := s.LikeQuote(ctx, int(id), subject); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.UnlikeQuote(ctx, int(id), "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	quote, err := s.GetQuote(ctx, int(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Likes != 1 {
+		t.Fatalf("likes = %d, want 1", quote.Likes)
+	}
+}
+
+// Вес отметки падает вдвое за каждый halfLife: три отметки возрастом
+// 2·halfLife весят 3/4 и уступают одной свежей.
+func TestPopularQuotesDecayExponentially(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	halfLife := time.Hour
+
+	fresh, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "fresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.LikeQuote(ctx, int(fresh), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		if err := s.LikeQuote(ctx, int(old), fmt.Sprintf("reader%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.client.ExecContext(ctx,
+		`UPDATE quote_likes SET created_at = strftime('%Y-%m-%d %H:%M:%f', 'now', '-2 hours') WHERE quote_id = ?`,
+		old,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	quotes, total, err := s.PopularQuotes(ctx, halfLife, models.QuoteFilter{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(quotes) != 2 {
+		t.Fatalf("got %d of %d quotes, want 2", len(quotes), total)
+	}
+	if int64(quotes[0].Id) != fresh || int64(quotes[1].Id) != old {
+		t.Fatalf("order = [%d %d], want [%d %d]", quotes[0].Id, quotes[1].Id, fresh, old)
+	}
+}
+
+func TestLikesWriteAudit(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "audited"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mirrored []models.AuditEntry
+	s.OnAudit(func(e models.AuditEntry) { mirrored = append(mirrored, e) })
+
+	// Повтор и снятие чужой отметки ничего не меняют и в журнал не попадают
+	for _, change := range []func(context.Context, int, string) error{s.LikeQuote, s.LikeQuote, s.UnlikeQuote} {
+		if err := change(ctx, int(id), "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.UnlikeQuote(ctx, int(id), "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, total, err := s.ListAudit(ctx, models.AuditFilter{EntityID: id, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || entries[0].Action != models.AuditQuoteUnlike || entries[1].Action != models.AuditQuoteLike {
+		t.Fatalf("entries = %+v, want create, like and unlike", entries)
+	}
+	if entries[1].Before != nil || string(entries[1].After) != `{"actor":"alice","quote_id":`+fmt.Sprint(id)+`}` {
+		t.Errorf("like entry before = %s, after = %s", entries[1].Before, entries[1].After)
+	}
+	if entries[0].After != nil || entries[0].Before == nil {
+		t.Errorf("unlike entry before = %s, after = %s", entries[0].Before, entries[0].After)
+	}
+	if len(mirrored) != 2 || mirrored[0].ID != entries[1].ID || mirrored[1].ID != entries[0].ID {
+		t.Errorf("mirrored %+v, want like and unlike", mirrored)
+	}
+}
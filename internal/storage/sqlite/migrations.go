@@ -0,0 +1,242 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrMigrationsPending = errors.New("database migrations not applied")
+
+// Миграции применяются по порядку, номер последней применённой
+// миграции хранится в PRAGMA user_version.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS quotes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	author VARCHAR(100) NOT NULL,
+	quote TEXT NOT NULL,
+	CONSTRAINT unique_quote UNIQUE (author, quote)
+	);`,
+	// Версии строк и счётчик изменений коллекции для ETag
+	`ALTER TABLE quotes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	CREATE TABLE collection_versions (
+	name TEXT PRIMARY KEY,
+	version INTEGER NOT NULL
+	);
+	INSERT INTO collection_versions (name, version) VALUES ('quotes', 1);
+	CREATE TRIGGER quotes_collection_insert AFTER INSERT ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;
+	CREATE TRIGGER quotes_collection_update AFTER UPDATE ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;
+	CREATE TRIGGER quotes_collection_delete AFTER DELETE ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;`,
+	// Мягкое удаление. Ограничение уникальности заменяется частичным
+	// индексом, чтобы удалённая цитата не мешала добавить такую же заново.
+	// SQLite не умеет удалять ограничения, поэтому таблица пересоздаётся.
+	// Счётчик AUTOINCREMENT переносится и для пустой таблицы: строки
+	// quotes_new в sqlite_sequence тогда ещё нет, и без неё идентификаторы
+	// удалённых цитат начали бы выдаваться заново.
+	`CREATE TABLE quotes_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	author VARCHAR(100) NOT NULL,
+	quote TEXT NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	deleted_at DATETIME,
+	deleted_by TEXT
+	);
+	INSERT INTO quotes_new (id, author, quote, version)
+		SELECT id, author, quote, version FROM quotes;
+	INSERT INTO sqlite_sequence (name, seq)
+		SELECT 'quotes_new', 0
+		WHERE NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'quotes_new');
+	UPDATE sqlite_sequence
+		SET seq = MAX(seq, COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'quotes'), 0))
+		WHERE name = 'quotes_new';
+	DROP TABLE quotes;
+	ALTER TABLE quotes_new RENAME TO quotes;
+	CREATE UNIQUE INDEX quotes_active_unique ON quotes (author, quote) WHERE deleted_at IS NULL;
+	CREATE INDEX quotes_deleted_at ON quotes (deleted_at) WHERE deleted_at IS NOT NULL;
+	CREATE TRIGGER quotes_collection_insert AFTER INSERT ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;
+	CREATE TRIGGER quotes_collection_update AFTER UPDATE ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;
+	CREATE TRIGGER quotes_collection_delete AFTER DELETE ON quotes BEGIN
+		UPDATE collection_versions SET version = version + 1 WHERE name = 'quotes';
+	END;`,
+	// История изменений. Номер ревизии равен версии цитаты после изменения,
+	// текущее состояние существующих цитат записывается как snapshot.
+	`CREATE TABLE quote_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	quote_id INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	author VARCHAR(100) NOT NULL,
+	quote TEXT NOT NULL,
+	prev_author VARCHAR(100),
+	prev_quote TEXT,
+	actor TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	CONSTRAINT unique_revision UNIQUE (quote_id, revision)
+	);
+	INSERT INTO quote_revisions (quote_id, revision, action, author, quote, actor)
+		SELECT id, version, 'snapshot', author, quote, 'system' FROM quotes;`,
+	// Журнал аудита. Триггеры запрещают изменять и удалять записи.
+	`CREATE TABLE audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time DATETIME NOT NULL,
+	action TEXT NOT NULL,
+	entity_id INTEGER,
+	actor TEXT NOT NULL,
+	client_ip TEXT,
+	request_id TEXT,
+	before TEXT,
+	after TEXT
+	);
+	CREATE INDEX audit_log_time ON audit_log (time);
+	CREATE INDEX audit_log_actor ON audit_log (actor, time);
+	CREATE INDEX audit_log_entity ON audit_log (entity_id, time);
+	CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log BEGIN
+		SELECT RAISE(ABORT, 'audit log is append-only');
+	END;
+	CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log BEGIN
+		SELECT RAISE(ABORT, 'audit log is append-only');
+	END;`,
+	// Время создания и изменения. Существующие цитаты получают время из
+	// истории. Индексы частичные, как и выборки: цитаты из корзины не сортируются.
+	`ALTER TABLE quotes ADD COLUMN created_at DATETIME;
+	ALTER TABLE quotes ADD COLUMN updated_at DATETIME;
+	UPDATE quotes SET
+		created_at = COALESCE(
+			(SELECT MIN(created_at) FROM quote_revisions WHERE quote_id = quotes.id),
+			CURRENT_TIMESTAMP),
+		updated_at = COALESCE(
+			(SELECT MAX(created_at) FROM quote_revisions
+			WHERE quote_id = quotes.id AND action IN ('create', 'update', 'revert', 'snapshot')),
+			CURRENT_TIMESTAMP);
+	CREATE INDEX quotes_created_at ON quotes (created_at) WHERE deleted_at IS NULL;
+	CREATE INDEX quotes_author_created_at ON quotes (author, created_at) WHERE deleted_at IS NULL;
+	CREATE INDEX quotes_length ON quotes (length(quote)) WHERE deleted_at IS NULL;`,
+	// Webhooks. События журнала изменений раскладываются по получателям в
+	// очередь webhook_deliveries, webhook_cursor хранит последнее разложенное.
+	// Подписка получает только события после своего создания (since_event_id).
+	`CREATE TABLE webhooks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL,
+	events TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	since_event_id INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+	);
+	CREATE TABLE webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_id INTEGER NOT NULL,
+	event_id INTEGER NOT NULL,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at DATETIME NOT NULL,
+	last_error TEXT,
+	created_at DATETIME NOT NULL,
+	delivered_at DATETIME,
+	CONSTRAINT unique_delivery UNIQUE (webhook_id, event_id)
+	);
+	CREATE INDEX webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+	CREATE INDEX webhook_deliveries_dead ON webhook_deliveries (id) WHERE status = 'dead';
+	CREATE TABLE webhook_attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	delivery_id INTEGER NOT NULL,
+	webhook_id INTEGER NOT NULL,
+	attempt INTEGER NOT NULL,
+	status_code INTEGER,
+	error TEXT,
+	duration_ms INTEGER NOT NULL,
+	created_at DATETIME NOT NULL
+	);
+	CREATE INDEX webhook_attempts_webhook ON webhook_attempts (webhook_id, id);
+	CREATE TABLE webhook_cursor (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	event_id INTEGER NOT NULL
+	);
+	INSERT INTO webhook_cursor (id, event_id) SELECT 1, COALESCE(MAX(id), 0) FROM quote_revisions;`,
+	// Ключи идемпотентности. Пока запрос выполняется, status равен NULL.
+	`CREATE TABLE idempotency_keys (
+	actor TEXT NOT NULL,
+	key TEXT NOT NULL,
+	request_hash TEXT NOT NULL,
+	status INTEGER,
+	headers TEXT,
+	body BLOB,
+	created_at DATETIME NOT NULL,
+	expires_at DATETIME NOT NULL,
+	PRIMARY KEY (actor, key)
+	);
+	CREATE INDEX idempotency_keys_expires_at ON idempotency_keys (expires_at);`,
+	// Отметки «нравится». Число отметок дублируется в quotes.likes, чтобы
+	// отдавать его вместе с цитатой без подсчёта.
+	`CREATE TABLE quote_likes (
+	quote_id INTEGER NOT NULL,
+	actor TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (quote_id, actor)
+	);
+	ALTER TABLE quotes ADD COLUMN likes INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX quotes_liked ON quotes (id) WHERE likes > 0 AND deleted_at IS NULL;
+	CREATE TRIGGER quote_likes_insert AFTER INSERT ON quote_likes BEGIN
+		UPDATE quotes SET likes = likes + 1 WHERE id = NEW.quote_id;
+	END;
+	CREATE TRIGGER quote_likes_delete AFTER DELETE ON quote_likes BEGIN
+		UPDATE quotes SET likes = likes - 1 WHERE id = OLD.quote_id;
+	END;`,
+	// Аренда ключа идемпотентности: ключ без ответа, аренда которого истекла,
+	// занимается повтором заново. Запросы, не завершённые до миграции,
+	// освобождаются сразу.
+	`ALTER TABLE idempotency_keys ADD COLUMN locked_until DATETIME;
+	UPDATE idempotency_keys SET locked_until = created_at WHERE status IS NULL;`,
+}
+
+func (s *Storage) migrate(ctx context.Context) error {
+	const op = sqliteOp + "migrate"
+
+	version, err := s.schemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.client.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: failed to apply migration %d: %w", op, i+1, err)
+		}
+
+		// PRAGMA не поддерживает плейсхолдеры
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: failed to set schema version %d: %w", op, i+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("%s: failed to commit migration %d: %w", op, i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) schemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.client.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/Grino777/quotes/internal/config"
+)
+
+// Идентификаторы удалённых до мягкого удаления цитат не выдаются заново,
+// даже если к миграции таблица quotes опустела.
+func TestSoftDeleteMigrationKeepsSequenceOfEmptyTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.sqlite")
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Схема до мягкого удаления: первые две миграции, цитаты удалены навсегда
+	for _, m := range migrations[:2] {
+		if _, err := db.ExecContext(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO quotes (author, quote) VALUES ('a', '1'), ('a', '2');
+	DELETE FROM quotes; PRAGMA user_version = 2`); err != nil {
+		t.Fatal(err)
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewStorage(log, &config.SQLiteConfig{Addr: path})
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	var seq int64
+	if err := s.client.QueryRowContext(ctx,
+		`SELECT seq FROM sqlite_sequence WHERE name = 'quotes'`).Scan(&seq); err != nil {
+		t.Fatal(err)
+	}
+	if seq != 2 {
+		t.Errorf("sequence = %d, want 2", seq)
+	}
+}
@@ -5,16 +5,25 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
 	"github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	ErrAlreadyExist   = errors.New("quote already exists")
-	ErrQuoteNotExists = errors.New("quote not exists")
+	ErrAlreadyExist    = errors.New("quote already exists")
+	ErrQuoteNotExists  = errors.New("quote not exists")
+	ErrVersionMismatch = errors.New("quote version mismatch")
+	// Запрошенной ревизии нет в истории цитаты
+	ErrRevisionNotExists = errors.New("revision not exists")
 )
 
 const (
@@ -23,48 +32,310 @@ const (
 
 const opQuotes = "storage.sqlite."
 
-func (s *Storage) GetQuotes(ctx context.Context) ([]models.Quote, error) {
+const quoteColumns = `id, author, quote, version, likes, created_at, updated_at, deleted_at, deleted_by`
+
+// likeEscaper экранирует служебные символы LIKE в подстроке поиска.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// now — текущее время UTC с миллисекундами для created_at и updated_at.
+const now = `strftime('%Y-%m-%d %H:%M:%f', 'now')`
+
+// sortColumns переводит сортировку из запроса в ORDER BY. Идентификатор
+// добавлен, чтобы порядок был стабильным при равных значениях.
+var sortColumns = map[models.QuoteSort]string{
+	models.SortDefault:       "id",
+	models.SortCreatedAt:     "created_at, id",
+	models.SortCreatedAtDesc: "created_at DESC, id DESC",
+	models.SortAuthor:        "author, id",
+	models.SortAuthorDesc:    "author DESC, id DESC",
+	models.SortLength:        "length(quote), id",
+	models.SortLengthDesc:    "length(quote) DESC, id DESC",
+}
+
+func orderBy(sort models.QuoteSort) string {
+	if clause, ok := sortColumns[sort]; ok {
+		return clause
+	}
+	return sortColumns[models.SortDefault]
+}
+
+// notDeleted отбирает цитаты, которых нет в корзине. Добавляется ко всем
+// запросам чтения и изменения.
+const notDeleted = `deleted_at IS NULL`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanQuote(row rowScanner) (models.Quote, error) {
+	var (
+		q         models.Quote
+		deletedAt sql.NullTime
+		deletedBy sql.NullString
+	)
+	if err := row.Scan(&q.Id, &q.Author, &q.Quote, &q.Version, &q.Likes,
+		&q.CreatedAt, &q.UpdatedAt, &deletedAt, &deletedBy); err != nil {
+		return q, err
+	}
+
+	if deletedAt.Valid {
+		q.DeletedAt = &deletedAt.Time
+	}
+	q.DeletedBy = deletedBy.String
+	return q, nil
+}
+
+func (s *Storage) GetQuotes(ctx context.Context, sort models.QuoteSort) ([]models.Quote, error) {
 	const op = opQuotes + "GetQuotes"
 
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
 	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
 	defer cancel()
 
-	stmt := `SELECT id, author, quote FROM quotes`
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes WHERE ` + notDeleted + ` ORDER BY ` + orderBy(sort)
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
 
 	rows, err := s.client.QueryContext(ctx, stmt)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to getting all quotes: %w", op, err)
+		return nil, logged(span, log, fmt.Errorf("%s: failed to getting all quotes: %w", op, err))
 	}
 	defer rows.Close()
 
 	var quotes []models.Quote
 
 	for rows.Next() {
-		var q models.Quote
-		if err := rows.Scan(&q.Id, &q.Author, &q.Quote); err != nil {
-			return nil, fmt.Errorf("%s: failed to scanning result: %w", op, err)
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, logged(span, log, fmt.Errorf("%s: failed to scanning result: %w", op, err))
 		}
 		quotes = append(quotes, q)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: failed to processing query result: %w", op, err)
+		return nil, logged(span, log, fmt.Errorf("%s: failed to processing query result: %w", op, err))
 	}
 
 	return quotes, nil
 }
 
+// ListQuotes возвращает страницу цитат и общее число цитат, подходящих под фильтр.
+func (s *Storage) ListQuotes(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error) {
+	const op = opQuotes + "ListQuotes"
+
+	where := notDeleted
+	var args []any
+	if filter.Author != "" {
+		where += ` AND author = ?`
+		args = append(args, filter.Author)
+	}
+	if filter.Query != "" {
+		where += ` AND (quote LIKE ? ESCAPE '\' OR author LIKE ? ESCAPE '\')`
+		pattern := "%" + likeEscaper.Replace(filter.Query) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	return s.listPage(ctx, op, where, args, orderBy(filter.Sort), filter)
+}
+
+// CountQuotesByAuthor считает цитаты каждого из авторов одним запросом.
+// Авторов без цитат в результате нет.
+func (s *Storage) CountQuotesByAuthor(ctx context.Context, authors []string) (map[string]int, error) {
+	const op = opQuotes + "CountQuotesByAuthor"
+
+	counts := make(map[string]int, len(authors))
+	if len(authors) == 0 {
+		return counts, nil
+	}
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT author, COUNT(*) FROM quotes WHERE ` + notDeleted +
+		` AND author IN (?` + strings.Repeat(`, ?`, len(authors)-1) + `) GROUP BY author`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	args := make([]any, len(authors))
+	for i, a := range authors {
+		args[i] = a
+	}
+
+	rows, err := s.client.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, logged(span, log, fmt.Errorf("%s: failed to count quotes: %w", op, err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			author string
+			n      int
+		)
+		if err := rows.Scan(&author, &n); err != nil {
+			return nil, logged(span, log, fmt.Errorf("%s: failed to scan count: %w", op, err))
+		}
+		counts[author] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
+	}
+
+	return counts, nil
+}
+
+// listPage выбирает страницу цитат по условию where и считает общее число
+// подходящих строк.
+func (s *Storage) listPage(
+	ctx context.Context,
+	op, where string,
+	args []any,
+	orderBy string,
+	filter models.QuoteFilter,
+) ([]models.Quote, int, error) {
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes WHERE ` + where +
+		` ORDER BY ` + orderBy + ` LIMIT ? OFFSET ?`
+	countStmt := `SELECT COUNT(*) FROM quotes WHERE ` + where
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var total int
+	if err := s.client.QueryRowContext(ctx, countStmt, args...).Scan(&total); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to count quotes: %w", op, err))
+	}
+
+	rows, err := s.client.QueryContext(ctx, stmt, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to query quotes: %w", op, err))
+	}
+	defer rows.Close()
+
+	quotes := make([]models.Quote, 0, filter.Limit)
+
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, 0, logged(span, log, fmt.Errorf("%s: failed to scan quote: %w", op, err))
+		}
+		quotes = append(quotes, q)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
+	}
+
+	return quotes, total, nil
+}
+
+func (s *Storage) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	const op = opQuotes + "GetQuote"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(getStmt)...)
+	defer span.End()
+
+	q, err := getQuote(ctx, s.client, op, id)
+	if err != nil {
+		return models.Quote{}, loggedUnexpected(span, log, err)
+	}
+
+	return q, nil
+}
+
+const getStmt = `SELECT ` + quoteColumns + ` FROM quotes WHERE id = ? AND ` + notDeleted
+
+func getQuote(ctx context.Context, q querier, op string, id int) (models.Quote, error) {
+	quote, err := scanQuote(q.QueryRowContext(ctx, getStmt, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Quote{}, ErrQuoteNotExists
+		}
+		return models.Quote{}, fmt.Errorf("%s: failed to scan quote: %w", op, err)
+	}
+	return quote, nil
+}
+
+// CollectionVersion возвращает счётчик изменений таблицы quotes. Счётчик
+// увеличивается триггерами на любую вставку, изменение и удаление.
+func (s *Storage) CollectionVersion(ctx context.Context) (int64, error) {
+	const op = opQuotes + "CollectionVersion"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT version FROM collection_versions WHERE name = 'quotes'`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var version int64
+	if err := s.client.QueryRowContext(ctx, stmt).Scan(&version); err != nil {
+		return 0, logged(span, log, fmt.Errorf("%s: failed to read collection version: %w", op, err))
+	}
+
+	return version, nil
+}
+
+// CreateQuote добавляет цитату и первую ревизию её истории в одной транзакции.
 func (s *Storage) CreateQuote(ctx context.Context, quote models.Quote) (int64, error) {
 	const op = opQuotes + "CreateQuote"
 
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
 	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
 	defer cancel()
 
-	stmt := `INSERT INTO quotes (author, quote) VALUES (?, ?)`
+	ctx, span := tracing.Start(ctx, op, dbAttrs(insertStmt)...)
+	defer span.End()
+
+	var (
+		id    int64
+		entry models.AuditEntry
+	)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if id, err = createQuote(ctx, tx, op, quote); err != nil {
+			return err
+		}
+		created, err := getQuote(ctx, tx, op, int(id))
+		if err != nil {
+			return err
+		}
+		entry, err = appendAudit(ctx, tx, op, models.AuditQuoteCreate, id, nil, created)
+		return err
+	})
+	if err != nil {
+		return 0, loggedUnexpected(span, log, err)
+	}
 
-	result, err := s.client.ExecContext(ctx, stmt, strings.ToLower(quote.Author), quote.Quote)
+	s.mirrorAudit(entry)
+	return id, nil
+}
+
+const insertStmt = `INSERT INTO quotes (author, quote, created_at, updated_at) VALUES (?, ?, ` + now + `, ` + now + `)`
+
+func createQuote(ctx context.Context, q querier, op string, quote models.Quote) (int64, error) {
+	result, err := q.ExecContext(ctx, insertStmt, strings.ToLower(quote.Author), quote.Quote)
 	if err != nil {
-		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.Code == sqlite3.ErrConstraint {
+		if isConstraintErr(err) {
 			return 0, ErrAlreadyExist
 		}
 		return 0, fmt.Errorf("%s: failed to insert quote: %w", op, err)
@@ -75,81 +346,270 @@ func (s *Storage) CreateQuote(ctx context.Context, quote models.Quote) (int64, e
 		return 0, fmt.Errorf("%s: failed to retrieve last insert ID: %w", op, err)
 	}
 
-	return id, nil
+	return id, recordRevision(ctx, q, op, id, models.RevisionCreate, nil)
+}
+
+// UpdateQuote заменяет текст и автора цитаты, увеличивает её версию и
+// записывает ревизию. Если version не 0, изменение применяется только к
+// цитате этой версии, иначе возвращается ErrVersionMismatch.
+func (s *Storage) UpdateQuote(ctx context.Context, id int, quote models.Quote, version int64) error {
+	const op = opQuotes + "UpdateQuote"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(updateStmt)...)
+	defer span.End()
+
+	var entry models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		entry, err = auditedUpdate(ctx, tx, op, models.AuditQuoteUpdate, id, func() error {
+			return updateQuote(ctx, tx, op, id, quote, version, models.RevisionUpdate)
+		})
+		return err
+	})
+	if err != nil {
+		return loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return nil
 }
 
-func (s *Storage) GetRandomQuote(ctx context.Context) (models.Quote, error) {
+// auditedUpdate выполняет изменение активной цитаты change и пишет в журнал
+// аудита цитату до и после него в той же транзакции.
+func auditedUpdate(
+	ctx context.Context,
+	tx querier,
+	op string,
+	action string,
+	id int,
+	change func() error,
+) (models.AuditEntry, error) {
+	before, err := getQuote(ctx, tx, op, id)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	if err := change(); err != nil {
+		return models.AuditEntry{}, err
+	}
+	after, err := getQuote(ctx, tx, op, id)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	return appendAudit(ctx, tx, op, action, int64(id), before, after)
+}
+
+const updateStmt = `UPDATE quotes SET author = ?, quote = ?, version = version + 1, updated_at = ` + now + `
+	WHERE id = ? AND ` + notDeleted + ` AND (? = 0 OR version = ?)`
+
+func updateQuote(
+	ctx context.Context,
+	tx querier,
+	op string,
+	id int,
+	quote models.Quote,
+	version int64,
+	action models.RevisionAction,
+) error {
+	prev, err := activeContent(ctx, tx, op, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, updateStmt,
+		strings.ToLower(quote.Author), quote.Quote, id, version, version)
+	if err != nil {
+		if isConstraintErr(err) {
+			return ErrAlreadyExist
+		}
+		return fmt.Errorf("%s: failed to update quote: %w", op, err)
+	}
+
+	if err := checkAffected(ctx, tx, op, result, id); err != nil {
+		return err
+	}
+
+	return recordRevision(ctx, tx, op, int64(id), action, &prev)
+}
+
+// GetRandomQuote возвращает случайную цитату, если author не пуст — только
+// среди цитат этого автора.
+func (s *Storage) GetRandomQuote(ctx context.Context, author string) (models.Quote, error) {
 	const op = opQuotes + "GetRandomQuote"
 
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
 	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
 	defer cancel()
 
-	stmt := `SELECT id, author, quote FROM quotes ORDER BY RANDOM() LIMIT 1`
+	where, args := notDeleted, []any{}
+	if author != "" {
+		where += ` AND author = ?`
+		args = append(args, author)
+	}
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes WHERE ` + where + ` ORDER BY RANDOM() LIMIT 1`
 
-	row := s.client.QueryRowContext(ctx, stmt)
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
 
-	var q models.Quote
-	if err := row.Scan(&q.Id, &q.Author, &q.Quote); err != nil {
+	q, err := scanQuote(s.client.QueryRowContext(ctx, stmt, args...))
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.Quote{}, ErrQuoteNotExists
 		}
-		return models.Quote{}, fmt.Errorf("%s: failed to scan random quote: %w", op, err)
+		return models.Quote{}, logged(span, log, fmt.Errorf("%s: failed to scan random quote: %w", op, err))
 	}
 
 	return q, nil
 }
 
-func (s *Storage) DeleteQuote(ctx context.Context, id int) error {
+// DeleteQuote переносит цитату в корзину, запоминая время и автора
+// удаления из контекста, и записывает ревизию. Если version не 0,
+// удаляется только цитата этой версии, иначе возвращается ErrVersionMismatch.
+func (s *Storage) DeleteQuote(ctx context.Context, id int, version int64) error {
 	const op = opQuotes + "DeleteQuote"
 
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
 	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
 	defer cancel()
 
-	stmt := `DELETE FROM quotes WHERE id = ?`
+	ctx, span := tracing.Start(ctx, op, dbAttrs(deleteStmt)...)
+	defer span.End()
 
-	result, err := s.client.ExecContext(ctx, stmt, id)
+	var entry models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := getQuote(ctx, tx, op, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteQuote(ctx, tx, op, id, version); err != nil {
+			return err
+		}
+		entry, err = appendAudit(ctx, tx, op, models.AuditQuoteDelete, int64(id), before, nil)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("%s: failed to delete quote: %w", op, err)
+		return loggedUnexpected(span, log, err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	s.mirrorAudit(entry)
+	return nil
+}
+
+const deleteStmt = `UPDATE quotes SET deleted_at = CURRENT_TIMESTAMP, deleted_by = ?, version = version + 1
+	WHERE id = ? AND ` + notDeleted + ` AND (? = 0 OR version = ?)`
+
+func deleteQuote(ctx context.Context, tx querier, op string, id int, version int64) error {
+	prev, err := activeContent(ctx, tx, op, id)
 	if err != nil {
-		return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		return err
 	}
-	if rowsAffected == 0 {
-		return ErrQuoteNotExists
+
+	result, err := tx.ExecContext(ctx, deleteStmt, actor.FromContext(ctx), id, version, version)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete quote: %w", op, err)
 	}
 
-	return nil
+	if err := checkAffected(ctx, tx, op, result, id); err != nil {
+		return err
+	}
+
+	return recordRevision(ctx, tx, op, int64(id), models.RevisionDelete, &prev)
 }
 
-func (s *Storage) FilterQuotes(ctx context.Context, author string) ([]models.Quote, error) {
+func (s *Storage) FilterQuotes(ctx context.Context, author string, sort models.QuoteSort) ([]models.Quote, error) {
 	const op = opQuotes + "FilterQuotes"
 
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
 	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
 	defer cancel()
 
-	stmt := `SELECT id, author, quote FROM quotes WHERE author = ?`
+	stmt := `SELECT ` + quoteColumns + ` FROM quotes WHERE author = ? AND ` + notDeleted + ` ORDER BY ` + orderBy(sort)
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
 
 	rows, err := s.client.QueryContext(ctx, stmt, author)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to query quotes by author: %w", op, err)
+		return nil, logged(span, log, fmt.Errorf("%s: failed to query quotes by author: %w", op, err))
 	}
 	defer rows.Close()
 
 	var quotes []models.Quote
 
 	for rows.Next() {
-		var q models.Quote
-		if err := rows.Scan(&q.Id, &q.Author, &q.Quote); err != nil {
-			return nil, fmt.Errorf("%s: failed to scan quote: %w", op, err)
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, logged(span, log, fmt.Errorf("%s: failed to scan quote: %w", op, err))
 		}
 		quotes = append(quotes, q)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: failed to process query result: %w", op, err)
+		return nil, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
 	}
 
 	return quotes, nil
 }
+
+// checkAffected различает отсутствие цитаты и несовпадение версии, когда
+// условный UPDATE не затронул ни одной строки.
+func checkAffected(ctx context.Context, q querier, op string, result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := q.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM quotes WHERE id = ? AND `+notDeleted+`)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("%s: failed to check quote existence: %w", op, err)
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrQuoteNotExists
+}
+
+func isConstraintErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint
+}
+
+// loggedUnexpected пропускает ожидаемые ошибки (нет цитаты, конфликт,
+// несовпадение версии) без записи в лог, остальные передаёт в logged.
+func loggedUnexpected(span trace.Span, log *slog.Logger, err error) error {
+	switch {
+	case errors.Is(err, ErrAlreadyExist),
+		errors.Is(err, ErrQuoteNotExists),
+		errors.Is(err, ErrVersionMismatch),
+		errors.Is(err, ErrRevisionNotExists),
+		errors.Is(err, ErrWebhookNotExists),
+		errors.Is(err, ErrDeliveryNotExists):
+		return err
+	}
+	return logged(span, log, err)
+}
+
+// logged пишет ошибку запроса в логгер и спан запроса и возвращает её без изменений.
+func logged(span trace.Span, log *slog.Logger, err error) error {
+	tracing.Error(span, err)
+	log.Error("query failed", logger.Error(err))
+	return err
+}
+
+func dbAttrs(stmt string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("db.system", "sqlite"),
+		attribute.String("db.query.text", stmt),
+	}
+}
@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/actor"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// querier — общее подмножество *sql.DB и *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx выполняет fn в транзакции и откатывает её, если fn вернула ошибку.
+func (s *Storage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.client.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// activeContent читает текущее содержимое цитаты не из корзины.
+func activeContent(ctx context.Context, q querier, op string, id int) (models.QuoteContent, error) {
+	var c models.QuoteContent
+	err := q.QueryRowContext(ctx,
+		`SELECT author, quote FROM quotes WHERE id = ? AND `+notDeleted, id).Scan(&c.Author, &c.Quote)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c, ErrQuoteNotExists
+		}
+		return c, fmt.Errorf("%s: failed to read quote: %w", op, err)
+	}
+	return c, nil
+}
+
+// recordRevision записывает состояние цитаты после изменения вместе с
+// предыдущим содержимым и автором изменения из контекста. Вызывается в той
+// же транзакции, что и само изменение.
+func recordRevision(
+	ctx context.Context,
+	q querier,
+	op string,
+	id int64,
+	action models.RevisionAction,
+	prev *models.QuoteContent,
+) error {
+	stmt := `INSERT INTO quote_revisions
+	(quote_id, revision, action, author, quote, prev_author, prev_quote, actor)
+	SELECT id, version, ?, author, quote, ?, ?, ? FROM quotes WHERE id = ?`
+
+	var prevAuthor, prevQuote sql.NullString
+	if prev != nil {
+		prevAuthor = sql.NullString{String: prev.Author, Valid: true}
+		prevQuote = sql.NullString{String: prev.Quote, Valid: true}
+	}
+
+	if _, err := q.ExecContext(ctx, stmt,
+		action, prevAuthor, prevQuote, actor.FromContext(ctx), id); err != nil {
+		return fmt.Errorf("%s: failed to record revision: %w", op, err)
+	}
+	return nil
+}
+
+// QuoteHistory возвращает страницу ревизий цитаты, новые первыми. История
+// доступна и для цитат в корзине; если ревизий нет, возвращается ErrQuoteNotExists.
+func (s *Storage) QuoteHistory(ctx context.Context, id int, limit, offset int) ([]models.QuoteRevision, int, error) {
+	const op = opQuotes + "QuoteHistory"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT quote_id, revision, action, author, quote, prev_author, prev_quote, actor, created_at
+	FROM quote_revisions WHERE quote_id = ? ORDER BY revision DESC LIMIT ? OFFSET ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var total int
+	if err := s.client.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM quote_revisions WHERE quote_id = ?`, id).Scan(&total); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to count revisions: %w", op, err))
+	}
+	if total == 0 {
+		return nil, 0, ErrQuoteNotExists
+	}
+
+	rows, err := s.client.QueryContext(ctx, stmt, id, limit, offset)
+	if err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to query revisions: %w", op, err))
+	}
+	defer rows.Close()
+
+	revisions := make([]models.QuoteRevision, 0, limit)
+
+	for rows.Next() {
+		var (
+			rev                   models.QuoteRevision
+			prevAuthor, prevQuote sql.NullString
+		)
+		if err := rows.Scan(&rev.QuoteID, &rev.Revision, &rev.Action,
+			&rev.Content.Author, &rev.Content.Quote, &prevAuthor, &prevQuote,
+			&rev.Actor, &rev.CreatedAt); err != nil {
+			return nil, 0, logged(span, log, fmt.Errorf("%s: failed to scan revision: %w", op, err))
+		}
+		if prevAuthor.Valid {
+			rev.Previous = &models.QuoteContent{Author: prevAuthor.String, Quote: prevQuote.String}
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
+	}
+
+	return revisions, total, nil
+}
+
+// RevertQuote возвращает цитате содержимое ревизии rev и записывает это
+// как новую ревизию. version работает как в UpdateQuote.
+func (s *Storage) RevertQuote(ctx context.Context, id int, rev int64, version int64) error {
+	const op = opQuotes + "RevertQuote"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT author, quote FROM quote_revisions WHERE quote_id = ? AND revision = ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var entry models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var target models.Quote
+		if err := tx.QueryRowContext(ctx, stmt, id, rev).Scan(&target.Author, &target.Quote); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrRevisionNotExists
+			}
+			return fmt.Errorf("%s: failed to read revision: %w", op, err)
+		}
+
+		var err error
+		entry, err = auditedUpdate(ctx, tx, op, models.AuditQuoteRevert, id, func() error {
+			return updateQuote(ctx, tx, op, id, target, version, models.RevisionRevert)
+		})
+		return err
+	})
+	if err != nil {
+		return loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return nil
+}
@@ -1,29 +1,41 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 
 	"github.com/Grino777/quotes/internal/config"
+	"github.com/Grino777/quotes/internal/domain/models"
 	"github.com/Grino777/quotes/internal/lib/logger"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
-const sqliteChema = `
-	CREATE TABLE IF NOT EXISTS quotes (
-	id INTEGER PRIMARY KEY AUTOINCREMENT,
-	author VARCHAR(100) NOT NULL,
-	quote TEXT NOT NULL,
-	CONSTRAINT unique_quote UNIQUE (author, quote)
-	);
-`
-
 const sqliteOp = "storage.sqlite."
 
+// driverName — драйвер go-sqlite3 с функцией exp: сборка SQLite без
+// SQLITE_ENABLE_MATH_FUNCTIONS её не содержит, а она нужна рейтингу отметок.
+const driverName = "sqlite3_quotes"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("exp", math.Exp, true)
+		},
+	})
+}
+
+var ErrNotConnected = errors.New("database not connected")
+
 type Storage struct {
 	logger *slog.Logger
 	cfg    *config.SQLiteConfig
 	client *sql.DB
+	// Получатель записей аудита после фиксации, см. OnAudit
+	onAudit func(models.AuditEntry)
 }
 
 func NewStorage(
@@ -41,18 +53,44 @@ func (s *Storage) Connect() error {
 
 	log := s.logger.With(slog.String("op", op))
 
-	conn, err := sql.Open("sqlite3", s.cfg.Addr)
+	// Изменения с историей читают и пишут в одной транзакции, поэтому
+	// блокировка на запись берётся сразу, а не при первой записи
+	conn, err := sql.Open(driverName, s.cfg.Addr+"?_txlock=immediate&_busy_timeout=5000")
 	if err != nil {
 		log.Error("failed to connect database", logger.Error(err))
 		return err
 	}
 
-	if _, err := conn.Exec(sqliteChema); err != nil {
-		log.Error("failed to create table for database", logger.Error(err))
+	s.client = conn
+
+	if err := s.migrate(context.Background()); err != nil {
+		log.Error("failed to apply migrations", logger.Error(err))
 		return err
 	}
 
-	s.client = conn
+	return nil
+}
+
+// Ping проверяет доступность базы и то, что все миграции применены.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = sqliteOp + "Ping"
+
+	if s.client == nil {
+		return fmt.Errorf("%s: %w", op, ErrNotConnected)
+	}
+
+	if err := s.client.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: failed to ping database: %w", op, err)
+	}
+
+	version, err := s.schemaVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if version < len(migrations) {
+		return fmt.Errorf("%s: %w: version %d of %d", op, ErrMigrationsPending, version, len(migrations))
+	}
+
 	return nil
 }
 
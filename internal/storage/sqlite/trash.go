@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+// ListTrash возвращает страницу цитат из корзины, по умолчанию недавно
+// удалённые первыми.
+func (s *Storage) ListTrash(ctx context.Context, filter models.QuoteFilter) ([]models.Quote, int, error) {
+	const op = opQuotes + "ListTrash"
+
+	where := `deleted_at IS NOT NULL`
+	var args []any
+	if filter.Author != "" {
+		where += ` AND author = ?`
+		args = append(args, filter.Author)
+	}
+
+	order := "deleted_at DESC, id DESC"
+	if filter.Sort != models.SortDefault {
+		order = orderBy(filter.Sort)
+	}
+
+	return s.listPage(ctx, op, where, args, order, filter)
+}
+
+// RestoreQuote возвращает цитату из корзины и записывает ревизию. Если за
+// это время добавили такую же цитату, возвращается ErrAlreadyExist.
+func (s *Storage) RestoreQuote(ctx context.Context, id int) error {
+	const op = opQuotes + "RestoreQuote"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `UPDATE quotes SET deleted_at = NULL, deleted_by = NULL, version = version + 1
+	WHERE id = ? AND deleted_at IS NOT NULL`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var entry models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		trashed, err := scanQuote(tx.QueryRowContext(ctx,
+			`SELECT `+quoteColumns+` FROM quotes WHERE id = ? AND deleted_at IS NOT NULL`, id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrQuoteNotExists
+			}
+			return fmt.Errorf("%s: failed to read quote: %w", op, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+			if isConstraintErr(err) {
+				return ErrAlreadyExist
+			}
+			return fmt.Errorf("%s: failed to restore quote: %w", op, err)
+		}
+
+		prev := models.QuoteContent{Author: trashed.Author, Quote: trashed.Quote}
+		if err := recordRevision(ctx, tx, op, int64(id), models.RevisionRestore, &prev); err != nil {
+			return err
+		}
+
+		restored, err := getQuote(ctx, tx, op, id)
+		if err != nil {
+			return err
+		}
+		entry, err = appendAudit(ctx, tx, op, models.AuditQuoteRestore, int64(id), trashed, restored)
+		return err
+	})
+	if err != nil {
+		return loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return nil
+}
+
+// PurgeTrash окончательно удаляет цитаты, попавшие в корзину раньше before,
+// вместе с их отметками и возвращает количество удалённых цитат. Ревизии
+// остаются: это журнал событий для потока и webhooks.
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	const op = opQuotes + "PurgeTrash"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `DELETE FROM quotes WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	// deleted_at заполняется CURRENT_TIMESTAMP, то есть в UTC и в этом формате
+	cutoff := before.UTC().Format(time.DateTime)
+
+	var (
+		purged int64
+		entry  models.AuditEntry
+	)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM quote_likes WHERE quote_id IN
+		(SELECT id FROM quotes WHERE deleted_at IS NOT NULL AND deleted_at < ?)`, cutoff); err != nil {
+			return fmt.Errorf("%s: failed to purge likes: %w", op, err)
+		}
+
+		result, err := tx.ExecContext(ctx, stmt, cutoff)
+		if err != nil {
+			return fmt.Errorf("%s: failed to purge trash: %w", op, err)
+		}
+
+		if purged, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		}
+
+		entry, err = appendAudit(ctx, tx, op, models.AuditTrashPurge, 0, nil, map[string]any{
+			"purged":         purged,
+			"deleted_before": before.UTC().Format(time.RFC3339),
+		})
+		return err
+	})
+	if err != nil {
+		return 0, logged(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return purged, nil
+}
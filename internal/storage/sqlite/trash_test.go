@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+)
+
+func TestPurgeTrashKeepsRevisions(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateQuote(ctx, models.Quote{Author: "seneca", Quote: "purged"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteQuote(ctx, int(id), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Fatalf("purged %d quotes, want 1", purged)
+	}
+
+	events, err := s.QuoteEvents(ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != models.EventQuoteCreated || events[1].Type != models.EventQuoteDeleted {
+		t.Fatalf("events = %+v, want created and deleted", events)
+	}
+
+	if _, total, err := s.QuoteHistory(ctx, int(id), 10, 0); err != nil || total != 2 {
+		t.Fatalf("history total = %d, err = %v, want 2 revisions", total, err)
+	}
+	if err := s.RevertQuote(ctx, int(id), 1, 0); !errors.Is(err, ErrQuoteNotExists) {
+		t.Fatalf("revert of purged quote: err = %v, want ErrQuoteNotExists", err)
+	}
+}
@@ -0,0 +1,531 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Grino777/quotes/internal/domain/models"
+	"github.com/Grino777/quotes/internal/lib/logger"
+	"github.com/Grino777/quotes/internal/lib/tracing"
+)
+
+var (
+	ErrWebhookNotExists = errors.New("webhook not exists")
+	// Доставки нет или она не в списке недоставленных
+	ErrDeliveryNotExists = errors.New("webhook delivery not exists")
+)
+
+const webhookColumns = `id, url, events, secret, created_at`
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_error, created_at, delivered_at`
+
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var (
+		w      models.Webhook
+		events string
+	)
+	if err := row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.CreatedAt); err != nil {
+		return w, err
+	}
+	w.Events = strings.Split(events, ",")
+	return w, nil
+}
+
+func scanDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var (
+		d           models.WebhookDelivery
+		payload     string
+		lastError   sql.NullString
+		deliveredAt sql.NullTime
+	)
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status,
+		&d.Attempts, &d.NextAttemptAt, &lastError, &d.CreatedAt, &deliveredAt); err != nil {
+		return d, err
+	}
+	d.Payload = json.RawMessage(payload)
+	d.LastError = lastError.String
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+// CreateWebhook добавляет подписку и возвращает её идентификатор. Подписка
+// получит только события, записанные после её создания.
+func (s *Storage) CreateWebhook(ctx context.Context, w models.Webhook) (int64, error) {
+	const op = opQuotes + "CreateWebhook"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `INSERT INTO webhooks (url, events, secret, since_event_id, created_at)
+	VALUES (?, ?, ?, (SELECT COALESCE(MAX(id), 0) FROM quote_revisions), ` + now + `)`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var (
+		id    int64
+		entry models.AuditEntry
+	)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, stmt, w.URL, strings.Join(w.Events, ","), w.Secret)
+		if err != nil {
+			return fmt.Errorf("%s: failed to insert webhook: %w", op, err)
+		}
+
+		if id, err = result.LastInsertId(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve last insert ID: %w", op, err)
+		}
+
+		created, err := getWebhook(ctx, tx, op, id)
+		if err != nil {
+			return err
+		}
+		created.Secret = ""
+		entry, err = appendAudit(ctx, tx, op, models.AuditWebhookCreate, id, nil, created)
+		return err
+	})
+	if err != nil {
+		return 0, logged(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return id, nil
+}
+
+// ListWebhooks возвращает все подписки вместе с секретами.
+func (s *Storage) ListWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	const op = opQuotes + "ListWebhooks"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op)
+	defer span.End()
+
+	webhooks, err := listWebhooks(ctx, s.client)
+	if err != nil {
+		return nil, logged(span, log, fmt.Errorf("%s: %w", op, err))
+	}
+	return webhooks, nil
+}
+
+func listWebhooks(ctx context.Context, q querier) ([]models.Webhook, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to process query result: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (s *Storage) GetWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	const op = opQuotes + "GetWebhook"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(getWebhookStmt)...)
+	defer span.End()
+
+	w, err := getWebhook(ctx, s.client, op, id)
+	if err != nil {
+		return models.Webhook{}, loggedUnexpected(span, log, err)
+	}
+
+	return w, nil
+}
+
+const getWebhookStmt = `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+func getWebhook(ctx context.Context, q querier, op string, id int64) (models.Webhook, error) {
+	w, err := scanWebhook(q.QueryRowContext(ctx, getWebhookStmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Webhook{}, ErrWebhookNotExists
+		}
+		return models.Webhook{}, fmt.Errorf("%s: failed to scan webhook: %w", op, err)
+	}
+	return w, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её очередью и журналом доставки.
+func (s *Storage) DeleteWebhook(ctx context.Context, id int64) error {
+	const op = opQuotes + "DeleteWebhook"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `DELETE FROM webhooks WHERE id = ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var entry models.AuditEntry
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		before, err := getWebhook(ctx, tx, op, id)
+		if err != nil {
+			return err
+		}
+		before.Secret = ""
+
+		if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+			return fmt.Errorf("%s: failed to delete webhook: %w", op, err)
+		}
+
+		for _, table := range []string{"webhook_attempts", "webhook_deliveries"} {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE webhook_id = ?`, id); err != nil {
+				return fmt.Errorf("%s: failed to delete from %s: %w", op, table, err)
+			}
+		}
+
+		entry, err = appendAudit(ctx, tx, op, models.AuditWebhookDelete, id, before, nil)
+		return err
+	})
+	if err != nil {
+		return loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return nil
+}
+
+// EnqueueWebhookEvents раскладывает до limit событий журнала изменений,
+// ещё не разложенных по подпискам, в очередь доставки и возвращает число
+// обработанных событий. Очередь и курсор меняются в одной транзакции,
+// поэтому событие не теряется и не дублируется при сбое.
+func (s *Storage) EnqueueWebhookEvents(ctx context.Context, limit int) (int, error) {
+	const op = opQuotes + "EnqueueWebhookEvents"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `INSERT OR IGNORE INTO webhook_deliveries
+	(webhook_id, event_id, event_type, payload, next_attempt_at, created_at)
+	SELECT id, ?, ?, ?, ` + now + `, ` + now + ` FROM webhooks WHERE id = ? AND since_event_id < ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var processed int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var cursor int64
+		if err := tx.QueryRowContext(ctx, `SELECT event_id FROM webhook_cursor WHERE id = 1`).Scan(&cursor); err != nil {
+			return fmt.Errorf("%s: failed to read cursor: %w", op, err)
+		}
+
+		events, err := queryEvents(ctx, tx, cursor, limit)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		webhooks, err := listWebhooks(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, event := range events {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("%s: failed to marshal event: %w", op, err)
+			}
+			for _, w := range webhooks {
+				if !w.Subscribed(event.Type) {
+					continue
+				}
+				if _, err := tx.ExecContext(ctx, stmt, event.ID, event.Type, string(payload), w.ID, event.ID); err != nil {
+					return fmt.Errorf("%s: failed to enqueue delivery: %w", op, err)
+				}
+			}
+		}
+
+		last := events[len(events)-1].ID
+		if _, err := tx.ExecContext(ctx, `UPDATE webhook_cursor SET event_id = ? WHERE id = 1`, last); err != nil {
+			return fmt.Errorf("%s: failed to move cursor: %w", op, err)
+		}
+		processed = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, logged(span, log, err)
+	}
+
+	return processed, nil
+}
+
+// DueWebhookDeliveries возвращает до limit ожидающих доставок, время
+// попытки которых наступило к now, начиная с самых давних.
+func (s *Storage) DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	const op = opQuotes + "DueWebhookDeliveries"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+	WHERE status = 'pending' AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	deliveries, err := s.queryDeliveries(ctx, stmt, now.UTC().Format(timeFormat), limit)
+	if err != nil {
+		return nil, logged(span, log, fmt.Errorf("%s: %w", op, err))
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt сохраняет результат попытки: новое состояние
+// доставки d и запись журнала a. Если подписку успели удалить, попытка не
+// записывается.
+func (s *Storage) RecordWebhookAttempt(ctx context.Context, d models.WebhookDelivery, a models.WebhookAttempt) error {
+	const op = opQuotes + "RecordWebhookAttempt"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?,
+	last_error = ?, delivered_at = ? WHERE id = ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var deliveredAt sql.NullString
+	if d.DeliveredAt != nil {
+		deliveredAt = sql.NullString{String: d.DeliveredAt.UTC().Format(timeFormat), Valid: true}
+	}
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, stmt,
+			d.Status,
+			d.Attempts,
+			d.NextAttemptAt.UTC().Format(timeFormat),
+			sql.NullString{String: d.LastError, Valid: d.LastError != ""},
+			deliveredAt,
+			d.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: failed to update delivery: %w", op, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		} else if n == 0 {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO webhook_attempts
+		(delivery_id, webhook_id, attempt, status_code, error, duration_ms, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			d.ID,
+			d.WebhookID,
+			a.Attempt,
+			sql.NullInt64{Int64: int64(a.StatusCode), Valid: a.StatusCode != 0},
+			sql.NullString{String: a.Error, Valid: a.Error != ""},
+			a.DurationMs,
+			a.Time.UTC().Format(timeFormat),
+		)
+		if err != nil {
+			return fmt.Errorf("%s: failed to insert attempt: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return logged(span, log, err)
+	}
+
+	return nil
+}
+
+// ListWebhookAttempts возвращает страницу журнала доставки подписки, новые
+// попытки первыми.
+func (s *Storage) ListWebhookAttempts(
+	ctx context.Context,
+	webhookID int64,
+	limit, offset int,
+) ([]models.WebhookAttempt, int, error) {
+	const op = opQuotes + "ListWebhookAttempts"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT a.id, a.delivery_id, a.webhook_id, d.event_id, d.event_type, a.attempt,
+	a.status_code, a.error, a.duration_ms, a.created_at
+	FROM webhook_attempts a JOIN webhook_deliveries d ON d.id = a.delivery_id
+	WHERE a.webhook_id = ? ORDER BY a.id DESC LIMIT ? OFFSET ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var total int
+	if err := s.client.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM webhook_attempts WHERE webhook_id = ?`, webhookID).Scan(&total); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to count attempts: %w", op, err))
+	}
+
+	rows, err := s.client.QueryContext(ctx, stmt, webhookID, limit, offset)
+	if err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to query attempts: %w", op, err))
+	}
+	defer rows.Close()
+
+	attempts := make([]models.WebhookAttempt, 0, limit)
+
+	for rows.Next() {
+		var (
+			a          models.WebhookAttempt
+			statusCode sql.NullInt64
+			attemptErr sql.NullString
+		)
+		if err := rows.Scan(&a.ID, &a.DeliveryID, &a.WebhookID, &a.EventID, &a.EventType, &a.Attempt,
+			&statusCode, &attemptErr, &a.DurationMs, &a.Time); err != nil {
+			return nil, 0, logged(span, log, fmt.Errorf("%s: failed to scan attempt: %w", op, err))
+		}
+		a.StatusCode = int(statusCode.Int64)
+		a.Error = attemptErr.String
+		attempts = append(attempts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to process query result: %w", op, err))
+	}
+
+	return attempts, total, nil
+}
+
+// ListDeadDeliveries возвращает страницу недоставленных событий, последние
+// первыми.
+func (s *Storage) ListDeadDeliveries(ctx context.Context, limit, offset int) ([]models.WebhookDelivery, int, error) {
+	const op = opQuotes + "ListDeadDeliveries"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+	WHERE status = 'dead' ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var total int
+	if err := s.client.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'dead'`).Scan(&total); err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: failed to count deliveries: %w", op, err))
+	}
+
+	deliveries, err := s.queryDeliveries(ctx, stmt, limit, offset)
+	if err != nil {
+		return nil, 0, logged(span, log, fmt.Errorf("%s: %w", op, err))
+	}
+	return deliveries, total, nil
+}
+
+// RetryDelivery возвращает недоставленное событие в очередь с обнулённым
+// счётчиком попыток.
+func (s *Storage) RetryDelivery(ctx context.Context, id int64) (models.WebhookDelivery, error) {
+	const op = opQuotes + "RetryDelivery"
+
+	log := logger.FromContext(ctx, s.logger).With(slog.String("op", op))
+
+	ctx, cancel := context.WithTimeout(ctx, ReqDuration*time.Second)
+	defer cancel()
+
+	stmt := `UPDATE webhook_deliveries SET status = 'pending', attempts = 0,
+	next_attempt_at = ` + now + `, last_error = NULL WHERE id = ? AND status = 'dead'`
+
+	ctx, span := tracing.Start(ctx, op, dbAttrs(stmt)...)
+	defer span.End()
+
+	var (
+		d     models.WebhookDelivery
+		entry models.AuditEntry
+	)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, stmt, id)
+		if err != nil {
+			return fmt.Errorf("%s: failed to update delivery: %w", op, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("%s: failed to retrieve rows affected: %w", op, err)
+		} else if n == 0 {
+			return ErrDeliveryNotExists
+		}
+
+		d, err = scanDelivery(tx.QueryRowContext(ctx,
+			`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id))
+		if err != nil {
+			return fmt.Errorf("%s: failed to scan delivery: %w", op, err)
+		}
+
+		entry, err = appendAudit(ctx, tx, op, models.AuditWebhookRetry, id, nil, d)
+		return err
+	})
+	if err != nil {
+		return models.WebhookDelivery{}, loggedUnexpected(span, log, err)
+	}
+
+	s.mirrorAudit(entry)
+	return d, nil
+}
+
+func (s *Storage) queryDeliveries(ctx context.Context, stmt string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := s.client.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to process query result: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,4 @@
+// Package quotesv1 содержит сгенерированный код gRPC API цитат.
+package quotesv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative quotes/v1/quotes.proto
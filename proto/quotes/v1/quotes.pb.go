@@ -0,0 +1,963 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: quotes/v1/quotes.proto
+
+package quotesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Порядок выдачи списка, совпадает с параметром sort HTTP API.
+type QuoteSort int32
+
+const (
+	QuoteSort_QUOTE_SORT_UNSPECIFIED     QuoteSort = 0
+	QuoteSort_QUOTE_SORT_CREATED_AT      QuoteSort = 1
+	QuoteSort_QUOTE_SORT_CREATED_AT_DESC QuoteSort = 2
+	QuoteSort_QUOTE_SORT_AUTHOR          QuoteSort = 3
+	QuoteSort_QUOTE_SORT_AUTHOR_DESC     QuoteSort = 4
+	QuoteSort_QUOTE_SORT_LENGTH          QuoteSort = 5
+	QuoteSort_QUOTE_SORT_LENGTH_DESC     QuoteSort = 6
+)
+
+// Enum value maps for QuoteSort.
+var (
+	QuoteSort_name = map[int32]string{
+		0: "QUOTE_SORT_UNSPECIFIED",
+		1: "QUOTE_SORT_CREATED_AT",
+		2: "QUOTE_SORT_CREATED_AT_DESC",
+		3: "QUOTE_SORT_AUTHOR",
+		4: "QUOTE_SORT_AUTHOR_DESC",
+		5: "QUOTE_SORT_LENGTH",
+		6: "QUOTE_SORT_LENGTH_DESC",
+	}
+	QuoteSort_value = map[string]int32{
+		"QUOTE_SORT_UNSPECIFIED":     0,
+		"QUOTE_SORT_CREATED_AT":      1,
+		"QUOTE_SORT_CREATED_AT_DESC": 2,
+		"QUOTE_SORT_AUTHOR":          3,
+		"QUOTE_SORT_AUTHOR_DESC":     4,
+		"QUOTE_SORT_LENGTH":          5,
+		"QUOTE_SORT_LENGTH_DESC":     6,
+	}
+)
+
+func (x QuoteSort) Enum() *QuoteSort {
+	p := new(QuoteSort)
+	*p = x
+	return p
+}
+
+func (x QuoteSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (QuoteSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_quotes_v1_quotes_proto_enumTypes[0].Descriptor()
+}
+
+func (QuoteSort) Type() protoreflect.EnumType {
+	return &file_quotes_v1_quotes_proto_enumTypes[0]
+}
+
+func (x QuoteSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use QuoteSort.Descriptor instead.
+func (QuoteSort) EnumDescriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{0}
+}
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	EventType_EVENT_TYPE_CREATED     EventType = 1
+	EventType_EVENT_TYPE_UPDATED     EventType = 2
+	EventType_EVENT_TYPE_DELETED     EventType = 3
+	EventType_EVENT_TYPE_RESTORED    EventType = 4
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_CREATED",
+		2: "EVENT_TYPE_UPDATED",
+		3: "EVENT_TYPE_DELETED",
+		4: "EVENT_TYPE_RESTORED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_CREATED":     1,
+		"EVENT_TYPE_UPDATED":     2,
+		"EVENT_TYPE_DELETED":     3,
+		"EVENT_TYPE_RESTORED":    4,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_quotes_v1_quotes_proto_enumTypes[1].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_quotes_v1_quotes_proto_enumTypes[1]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{1}
+}
+
+type Quote struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Author string                 `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	Quote  string                 `protobuf:"bytes,3,opt,name=quote,proto3" json:"quote,omitempty"`
+	// Версия строки для условного удаления
+	Version   int64                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Число отметок «нравится»
+	Likes         int64 `protobuf:"varint,7,opt,name=likes,proto3" json:"likes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quote) Reset() {
+	*x = Quote{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quote) ProtoMessage() {}
+
+func (x *Quote) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quote.ProtoReflect.Descriptor instead.
+func (*Quote) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Quote) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Quote) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Quote) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *Quote) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Quote) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Quote) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Quote) GetLikes() int64 {
+	if x != nil {
+		return x.Likes
+	}
+	return 0
+}
+
+type GetQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuoteRequest) Reset() {
+	*x = GetQuoteRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuoteRequest) ProtoMessage() {}
+
+func (x *GetQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuoteRequest.ProtoReflect.Descriptor instead.
+func (*GetQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetQuoteRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListQuotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 — размер страницы по умолчанию
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token из предыдущего ответа
+	PageToken     string    `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Author        string    `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Sort          QuoteSort `protobuf:"varint,4,opt,name=sort,proto3,enum=quotes.v1.QuoteSort" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuotesRequest) Reset() {
+	*x = ListQuotesRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuotesRequest) ProtoMessage() {}
+
+func (x *ListQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuotesRequest.ProtoReflect.Descriptor instead.
+func (*ListQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListQuotesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListQuotesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListQuotesRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ListQuotesRequest) GetSort() QuoteSort {
+	if x != nil {
+		return x.Sort
+	}
+	return QuoteSort_QUOTE_SORT_UNSPECIFIED
+}
+
+type ListQuotesResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Quotes []*Quote               `protobuf:"bytes,1,rep,name=quotes,proto3" json:"quotes,omitempty"`
+	// Пусто на последней странице
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQuotesResponse) Reset() {
+	*x = ListQuotesResponse{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQuotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQuotesResponse) ProtoMessage() {}
+
+func (x *ListQuotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQuotesResponse.ProtoReflect.Descriptor instead.
+func (*ListQuotesResponse) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListQuotesResponse) GetQuotes() []*Quote {
+	if x != nil {
+		return x.Quotes
+	}
+	return nil
+}
+
+func (x *ListQuotesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListQuotesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+type CreateQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	Quote         string                 `protobuf:"bytes,2,opt,name=quote,proto3" json:"quote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateQuoteRequest) Reset() {
+	*x = CreateQuoteRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateQuoteRequest) ProtoMessage() {}
+
+func (x *CreateQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateQuoteRequest.ProtoReflect.Descriptor instead.
+func (*CreateQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateQuoteRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CreateQuoteRequest) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+type DeleteQuoteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Если задана, цитата удаляется только при совпадении версии
+	Version       int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuoteRequest) Reset() {
+	*x = DeleteQuoteRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuoteRequest) ProtoMessage() {}
+
+func (x *DeleteQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeleteQuoteRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteQuoteRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DeleteQuoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteQuoteResponse) Reset() {
+	*x = DeleteQuoteResponse{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteQuoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteQuoteResponse) ProtoMessage() {}
+
+func (x *DeleteQuoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteQuoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteQuoteResponse) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{6}
+}
+
+type RandomQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Author        string                 `protobuf:"bytes,1,opt,name=author,proto3" json:"author,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomQuoteRequest) Reset() {
+	*x = RandomQuoteRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomQuoteRequest) ProtoMessage() {}
+
+func (x *RandomQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomQuoteRequest.ProtoReflect.Descriptor instead.
+func (*RandomQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RandomQuoteRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+type SearchQuotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Sort          QuoteSort              `protobuf:"varint,4,opt,name=sort,proto3,enum=quotes.v1.QuoteSort" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchQuotesRequest) Reset() {
+	*x = SearchQuotesRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchQuotesRequest) ProtoMessage() {}
+
+func (x *SearchQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchQuotesRequest.ProtoReflect.Descriptor instead.
+func (*SearchQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchQuotesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchQuotesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchQuotesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchQuotesRequest) GetSort() QuoteSort {
+	if x != nil {
+		return x.Sort
+	}
+	return QuoteSort_QUOTE_SORT_UNSPECIFIED
+}
+
+type WatchQuotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Без значения — только новые события, 0 — весь журнал
+	AfterEventId  *int64 `protobuf:"varint,1,opt,name=after_event_id,json=afterEventId,proto3,oneof" json:"after_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchQuotesRequest) Reset() {
+	*x = WatchQuotesRequest{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchQuotesRequest) ProtoMessage() {}
+
+func (x *WatchQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchQuotesRequest.ProtoReflect.Descriptor instead.
+func (*WatchQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchQuotesRequest) GetAfterEventId() int64 {
+	if x != nil && x.AfterEventId != nil {
+		return *x.AfterEventId
+	}
+	return 0
+}
+
+type QuoteEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          EventType              `protobuf:"varint,2,opt,name=type,proto3,enum=quotes.v1.EventType" json:"type,omitempty"`
+	QuoteId       int32                  `protobuf:"varint,3,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Quote         string                 `protobuf:"bytes,5,opt,name=quote,proto3" json:"quote,omitempty"`
+	Actor         string                 `protobuf:"bytes,6,opt,name=actor,proto3" json:"actor,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteEvent) Reset() {
+	*x = QuoteEvent{}
+	mi := &file_quotes_v1_quotes_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteEvent) ProtoMessage() {}
+
+func (x *QuoteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_quotes_v1_quotes_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteEvent.ProtoReflect.Descriptor instead.
+func (*QuoteEvent) Descriptor() ([]byte, []int) {
+	return file_quotes_v1_quotes_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *QuoteEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QuoteEvent) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *QuoteEvent) GetQuoteId() int32 {
+	if x != nil {
+		return x.QuoteId
+	}
+	return 0
+}
+
+func (x *QuoteEvent) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *QuoteEvent) GetQuote() string {
+	if x != nil {
+		return x.Quote
+	}
+	return ""
+}
+
+func (x *QuoteEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *QuoteEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_quotes_v1_quotes_proto protoreflect.FileDescriptor
+
+const file_quotes_v1_quotes_proto_rawDesc = "" +
+	"\n" +
+	"\x16quotes/v1/quotes.proto\x12\tquotes.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xeb\x01\n" +
+	"\x05Quote\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x16\n" +
+	"\x06author\x18\x02 \x01(\tR\x06author\x12\x14\n" +
+	"\x05quote\x18\x03 \x01(\tR\x05quote\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x14\n" +
+	"\x05likes\x18\a \x01(\x03R\x05likes\"!\n" +
+	"\x0fGetQuoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\x91\x01\n" +
+	"\x11ListQuotesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12(\n" +
+	"\x04sort\x18\x04 \x01(\x0e2\x14.quotes.v1.QuoteSortR\x04sort\"\x85\x01\n" +
+	"\x12ListQuotesResponse\x12(\n" +
+	"\x06quotes\x18\x01 \x03(\v2\x10.quotes.v1.QuoteR\x06quotes\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"B\n" +
+	"\x12CreateQuoteRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\x12\x14\n" +
+	"\x05quote\x18\x02 \x01(\tR\x05quote\">\n" +
+	"\x12DeleteQuoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"\x15\n" +
+	"\x13DeleteQuoteResponse\",\n" +
+	"\x12RandomQuoteRequest\x12\x16\n" +
+	"\x06author\x18\x01 \x01(\tR\x06author\"\x91\x01\n" +
+	"\x13SearchQuotesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x12(\n" +
+	"\x04sort\x18\x04 \x01(\x0e2\x14.quotes.v1.QuoteSortR\x04sort\"R\n" +
+	"\x12WatchQuotesRequest\x12)\n" +
+	"\x0eafter_event_id\x18\x01 \x01(\x03H\x00R\fafterEventId\x88\x01\x01B\x11\n" +
+	"\x0f_after_event_id\"\xd5\x01\n" +
+	"\n" +
+	"QuoteEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12(\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x14.quotes.v1.EventTypeR\x04type\x12\x19\n" +
+	"\bquote_id\x18\x03 \x01(\x05R\aquoteId\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
+	"\x05quote\x18\x05 \x01(\tR\x05quote\x12\x14\n" +
+	"\x05actor\x18\x06 \x01(\tR\x05actor\x12.\n" +
+	"\x04time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x04time*\xc8\x01\n" +
+	"\tQuoteSort\x12\x1a\n" +
+	"\x16QUOTE_SORT_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15QUOTE_SORT_CREATED_AT\x10\x01\x12\x1e\n" +
+	"\x1aQUOTE_SORT_CREATED_AT_DESC\x10\x02\x12\x15\n" +
+	"\x11QUOTE_SORT_AUTHOR\x10\x03\x12\x1a\n" +
+	"\x16QUOTE_SORT_AUTHOR_DESC\x10\x04\x12\x15\n" +
+	"\x11QUOTE_SORT_LENGTH\x10\x05\x12\x1a\n" +
+	"\x16QUOTE_SORT_LENGTH_DESC\x10\x06*\x88\x01\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12EVENT_TYPE_CREATED\x10\x01\x12\x16\n" +
+	"\x12EVENT_TYPE_UPDATED\x10\x02\x12\x16\n" +
+	"\x12EVENT_TYPE_DELETED\x10\x03\x12\x17\n" +
+	"\x13EVENT_TYPE_RESTORED\x10\x042\xf8\x03\n" +
+	"\rQuotesService\x128\n" +
+	"\bGetQuote\x12\x1a.quotes.v1.GetQuoteRequest\x1a\x10.quotes.v1.Quote\x12I\n" +
+	"\n" +
+	"ListQuotes\x12\x1c.quotes.v1.ListQuotesRequest\x1a\x1d.quotes.v1.ListQuotesResponse\x12>\n" +
+	"\vCreateQuote\x12\x1d.quotes.v1.CreateQuoteRequest\x1a\x10.quotes.v1.Quote\x12L\n" +
+	"\vDeleteQuote\x12\x1d.quotes.v1.DeleteQuoteRequest\x1a\x1e.quotes.v1.DeleteQuoteResponse\x12>\n" +
+	"\vRandomQuote\x12\x1d.quotes.v1.RandomQuoteRequest\x1a\x10.quotes.v1.Quote\x12M\n" +
+	"\fSearchQuotes\x12\x1e.quotes.v1.SearchQuotesRequest\x1a\x1d.quotes.v1.ListQuotesResponse\x12E\n" +
+	"\vWatchQuotes\x12\x1d.quotes.v1.WatchQuotesRequest\x1a\x15.quotes.v1.QuoteEvent0\x01B5Z3github.com/Grino777/quotes/proto/quotes/v1;quotesv1b\x06proto3"
+
+var (
+	file_quotes_v1_quotes_proto_rawDescOnce sync.Once
+	file_quotes_v1_quotes_proto_rawDescData []byte
+)
+
+func file_quotes_v1_quotes_proto_rawDescGZIP() []byte {
+	file_quotes_v1_quotes_proto_rawDescOnce.Do(func() {
+		file_quotes_v1_quotes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_quotes_v1_quotes_proto_rawDesc), len(file_quotes_v1_quotes_proto_rawDesc)))
+	})
+	return file_quotes_v1_quotes_proto_rawDescData
+}
+
+var file_quotes_v1_quotes_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_quotes_v1_quotes_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_quotes_v1_quotes_proto_goTypes = []any{
+	(QuoteSort)(0),                // 0: quotes.v1.QuoteSort
+	(EventType)(0),                // 1: quotes.v1.EventType
+	(*Quote)(nil),                 // 2: quotes.v1.Quote
+	(*GetQuoteRequest)(nil),       // 3: quotes.v1.GetQuoteRequest
+	(*ListQuotesRequest)(nil),     // 4: quotes.v1.ListQuotesRequest
+	(*ListQuotesResponse)(nil),    // 5: quotes.v1.ListQuotesResponse
+	(*CreateQuoteRequest)(nil),    // 6: quotes.v1.CreateQuoteRequest
+	(*DeleteQuoteRequest)(nil),    // 7: quotes.v1.DeleteQuoteRequest
+	(*DeleteQuoteResponse)(nil),   // 8: quotes.v1.DeleteQuoteResponse
+	(*RandomQuoteRequest)(nil),    // 9: quotes.v1.RandomQuoteRequest
+	(*SearchQuotesRequest)(nil),   // 10: quotes.v1.SearchQuotesRequest
+	(*WatchQuotesRequest)(nil),    // 11: quotes.v1.WatchQuotesRequest
+	(*QuoteEvent)(nil),            // 12: quotes.v1.QuoteEvent
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+}
+var file_quotes_v1_quotes_proto_depIdxs = []int32{
+	13, // 0: quotes.v1.Quote.created_at:type_name -> google.protobuf.Timestamp
+	13, // 1: quotes.v1.Quote.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: quotes.v1.ListQuotesRequest.sort:type_name -> quotes.v1.QuoteSort
+	2,  // 3: quotes.v1.ListQuotesResponse.quotes:type_name -> quotes.v1.Quote
+	0,  // 4: quotes.v1.SearchQuotesRequest.sort:type_name -> quotes.v1.QuoteSort
+	1,  // 5: quotes.v1.QuoteEvent.type:type_name -> quotes.v1.EventType
+	13, // 6: quotes.v1.QuoteEvent.time:type_name -> google.protobuf.Timestamp
+	3,  // 7: quotes.v1.QuotesService.GetQuote:input_type -> quotes.v1.GetQuoteRequest
+	4,  // 8: quotes.v1.QuotesService.ListQuotes:input_type -> quotes.v1.ListQuotesRequest
+	6,  // 9: quotes.v1.QuotesService.CreateQuote:input_type -> quotes.v1.CreateQuoteRequest
+	7,  // 10: quotes.v1.QuotesService.DeleteQuote:input_type -> quotes.v1.DeleteQuoteRequest
+	9,  // 11: quotes.v1.QuotesService.RandomQuote:input_type -> quotes.v1.RandomQuoteRequest
+	10, // 12: quotes.v1.QuotesService.SearchQuotes:input_type -> quotes.v1.SearchQuotesRequest
+	11, // 13: quotes.v1.QuotesService.WatchQuotes:input_type -> quotes.v1.WatchQuotesRequest
+	2,  // 14: quotes.v1.QuotesService.GetQuote:output_type -> quotes.v1.Quote
+	5,  // 15: quotes.v1.QuotesService.ListQuotes:output_type -> quotes.v1.ListQuotesResponse
+	2,  // 16: quotes.v1.QuotesService.CreateQuote:output_type -> quotes.v1.Quote
+	8,  // 17: quotes.v1.QuotesService.DeleteQuote:output_type -> quotes.v1.DeleteQuoteResponse
+	2,  // 18: quotes.v1.QuotesService.RandomQuote:output_type -> quotes.v1.Quote
+	5,  // 19: quotes.v1.QuotesService.SearchQuotes:output_type -> quotes.v1.ListQuotesResponse
+	12, // 20: quotes.v1.QuotesService.WatchQuotes:output_type -> quotes.v1.QuoteEvent
+	14, // [14:21] is the sub-list for method output_type
+	7,  // [7:14] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_quotes_v1_quotes_proto_init() }
+func file_quotes_v1_quotes_proto_init() {
+	if File_quotes_v1_quotes_proto != nil {
+		return
+	}
+	file_quotes_v1_quotes_proto_msgTypes[9].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_quotes_v1_quotes_proto_rawDesc), len(file_quotes_v1_quotes_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_quotes_v1_quotes_proto_goTypes,
+		DependencyIndexes: file_quotes_v1_quotes_proto_depIdxs,
+		EnumInfos:         file_quotes_v1_quotes_proto_enumTypes,
+		MessageInfos:      file_quotes_v1_quotes_proto_msgTypes,
+	}.Build()
+	File_quotes_v1_quotes_proto = out.File
+	file_quotes_v1_quotes_proto_goTypes = nil
+	file_quotes_v1_quotes_proto_depIdxs = nil
+}
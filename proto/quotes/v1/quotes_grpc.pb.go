@@ -0,0 +1,369 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: quotes/v1/quotes.proto
+
+package quotesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	QuotesService_GetQuote_FullMethodName     = "/quotes.v1.QuotesService/GetQuote"
+	QuotesService_ListQuotes_FullMethodName   = "/quotes.v1.QuotesService/ListQuotes"
+	QuotesService_CreateQuote_FullMethodName  = "/quotes.v1.QuotesService/CreateQuote"
+	QuotesService_DeleteQuote_FullMethodName  = "/quotes.v1.QuotesService/DeleteQuote"
+	QuotesService_RandomQuote_FullMethodName  = "/quotes.v1.QuotesService/RandomQuote"
+	QuotesService_SearchQuotes_FullMethodName = "/quotes.v1.QuotesService/SearchQuotes"
+	QuotesService_WatchQuotes_FullMethodName  = "/quotes.v1.QuotesService/WatchQuotes"
+)
+
+// QuotesServiceClient is the client API for QuotesService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// QuotesService — gRPC-доступ к цитатам. Работает поверх того же сервисного
+// слоя, что и HTTP API, поэтому проверки, аудит и события у них общие.
+// Автор изменений передаётся в метаданных x-actor, идентификатор запроса —
+// в x-request-id.
+type QuotesServiceClient interface {
+	GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error)
+	CreateQuote(ctx context.Context, in *CreateQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	DeleteQuote(ctx context.Context, in *DeleteQuoteRequest, opts ...grpc.CallOption) (*DeleteQuoteResponse, error)
+	RandomQuote(ctx context.Context, in *RandomQuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+	// Поиск подстроки в тексте и авторе без учёта регистра латиницы.
+	SearchQuotes(ctx context.Context, in *SearchQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error)
+	// Поток изменений цитат. С after_event_id сначала отдаются пропущенные
+	// события из журнала изменений, без него — только новые.
+	WatchQuotes(ctx context.Context, in *WatchQuotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QuoteEvent], error)
+}
+
+type quotesServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQuotesServiceClient(cc grpc.ClientConnInterface) QuotesServiceClient {
+	return &quotesServiceClient{cc}
+}
+
+func (c *quotesServiceClient) GetQuote(ctx context.Context, in *GetQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, QuotesService_GetQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) ListQuotes(ctx context.Context, in *ListQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuotesResponse)
+	err := c.cc.Invoke(ctx, QuotesService_ListQuotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) CreateQuote(ctx context.Context, in *CreateQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, QuotesService_CreateQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) DeleteQuote(ctx context.Context, in *DeleteQuoteRequest, opts ...grpc.CallOption) (*DeleteQuoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQuoteResponse)
+	err := c.cc.Invoke(ctx, QuotesService_DeleteQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) RandomQuote(ctx context.Context, in *RandomQuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, QuotesService_RandomQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) SearchQuotes(ctx context.Context, in *SearchQuotesRequest, opts ...grpc.CallOption) (*ListQuotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQuotesResponse)
+	err := c.cc.Invoke(ctx, QuotesService_SearchQuotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *quotesServiceClient) WatchQuotes(ctx context.Context, in *WatchQuotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[QuoteEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &QuotesService_ServiceDesc.Streams[0], QuotesService_WatchQuotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchQuotesRequest, QuoteEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuotesService_WatchQuotesClient = grpc.ServerStreamingClient[QuoteEvent]
+
+// QuotesServiceServer is the server API for QuotesService service.
+// All implementations must embed UnimplementedQuotesServiceServer
+// for forward compatibility.
+//
+// QuotesService — gRPC-доступ к цитатам. Работает поверх того же сервисного
+// слоя, что и HTTP API, поэтому проверки, аудит и события у них общие.
+// Автор изменений передаётся в метаданных x-actor, идентификатор запроса —
+// в x-request-id.
+type QuotesServiceServer interface {
+	GetQuote(context.Context, *GetQuoteRequest) (*Quote, error)
+	ListQuotes(context.Context, *ListQuotesRequest) (*ListQuotesResponse, error)
+	CreateQuote(context.Context, *CreateQuoteRequest) (*Quote, error)
+	DeleteQuote(context.Context, *DeleteQuoteRequest) (*DeleteQuoteResponse, error)
+	RandomQuote(context.Context, *RandomQuoteRequest) (*Quote, error)
+	// Поиск подстроки в тексте и авторе без учёта регистра латиницы.
+	SearchQuotes(context.Context, *SearchQuotesRequest) (*ListQuotesResponse, error)
+	// Поток изменений цитат. С after_event_id сначала отдаются пропущенные
+	// события из журнала изменений, без него — только новые.
+	WatchQuotes(*WatchQuotesRequest, grpc.ServerStreamingServer[QuoteEvent]) error
+	mustEmbedUnimplementedQuotesServiceServer()
+}
+
+// UnimplementedQuotesServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedQuotesServiceServer struct{}
+
+func (UnimplementedQuotesServiceServer) GetQuote(context.Context, *GetQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedQuotesServiceServer) ListQuotes(context.Context, *ListQuotesRequest) (*ListQuotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQuotes not implemented")
+}
+func (UnimplementedQuotesServiceServer) CreateQuote(context.Context, *CreateQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateQuote not implemented")
+}
+func (UnimplementedQuotesServiceServer) DeleteQuote(context.Context, *DeleteQuoteRequest) (*DeleteQuoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteQuote not implemented")
+}
+func (UnimplementedQuotesServiceServer) RandomQuote(context.Context, *RandomQuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RandomQuote not implemented")
+}
+func (UnimplementedQuotesServiceServer) SearchQuotes(context.Context, *SearchQuotesRequest) (*ListQuotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchQuotes not implemented")
+}
+func (UnimplementedQuotesServiceServer) WatchQuotes(*WatchQuotesRequest, grpc.ServerStreamingServer[QuoteEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchQuotes not implemented")
+}
+func (UnimplementedQuotesServiceServer) mustEmbedUnimplementedQuotesServiceServer() {}
+func (UnimplementedQuotesServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeQuotesServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QuotesServiceServer will
+// result in compilation errors.
+type UnsafeQuotesServiceServer interface {
+	mustEmbedUnimplementedQuotesServiceServer()
+}
+
+func RegisterQuotesServiceServer(s grpc.ServiceRegistrar, srv QuotesServiceServer) {
+	// If the following call pancis, it indicates UnimplementedQuotesServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&QuotesService_ServiceDesc, srv)
+}
+
+func _QuotesService_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).GetQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_GetQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).GetQuote(ctx, req.(*GetQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_ListQuotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQuotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).ListQuotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_ListQuotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).ListQuotes(ctx, req.(*ListQuotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_CreateQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).CreateQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_CreateQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).CreateQuote(ctx, req.(*CreateQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_DeleteQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).DeleteQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_DeleteQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).DeleteQuote(ctx, req.(*DeleteQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_RandomQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomQuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).RandomQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_RandomQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).RandomQuote(ctx, req.(*RandomQuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_SearchQuotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchQuotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QuotesServiceServer).SearchQuotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: QuotesService_SearchQuotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QuotesServiceServer).SearchQuotes(ctx, req.(*SearchQuotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QuotesService_WatchQuotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchQuotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(QuotesServiceServer).WatchQuotes(m, &grpc.GenericServerStream[WatchQuotesRequest, QuoteEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type QuotesService_WatchQuotesServer = grpc.ServerStreamingServer[QuoteEvent]
+
+// QuotesService_ServiceDesc is the grpc.ServiceDesc for QuotesService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QuotesService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "quotes.v1.QuotesService",
+	HandlerType: (*QuotesServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQuote",
+			Handler:    _QuotesService_GetQuote_Handler,
+		},
+		{
+			MethodName: "ListQuotes",
+			Handler:    _QuotesService_ListQuotes_Handler,
+		},
+		{
+			MethodName: "CreateQuote",
+			Handler:    _QuotesService_CreateQuote_Handler,
+		},
+		{
+			MethodName: "DeleteQuote",
+			Handler:    _QuotesService_DeleteQuote_Handler,
+		},
+		{
+			MethodName: "RandomQuote",
+			Handler:    _QuotesService_RandomQuote_Handler,
+		},
+		{
+			MethodName: "SearchQuotes",
+			Handler:    _QuotesService_SearchQuotes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchQuotes",
+			Handler:       _QuotesService_WatchQuotes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "quotes/v1/quotes.proto",
+}